@@ -0,0 +1,201 @@
+package bwan
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// edgeItem describes the items of an edge list that are also managed one
+// at a time, e.g. static routes by netskopebwan_gateway_staticroute, while
+// the whole list may be managed by an authoritative resource, e.g.
+// netskopebwan_gateway_static_routes.
+type edgeItem struct {
+	// What is an item as named in messages, e.g. "static route", List the
+	// list, e.g. "static routes".
+	What string
+	List string
+	// Owner is the type of the authoritative resource of the list.
+	Owner string
+	// Key is the attribute of the single item resource identifying the
+	// item, e.g. "destination".
+	Key string
+	// Keys returns the keys of the items of the edge.
+	Keys func(gateway *swagger.Edge) []string
+}
+
+// checkNew is the CustomizeDiff of a resource managing a single item,
+// failing the plan of a new item that the edge already has. The item may
+// have been added in the portal, by another configuration or by the
+// authoritative resource of the list; the check relies on the edge alone,
+// so it does not depend on the order in which resources are planned.
+func (i edgeItem) checkNew() schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		if d.Id() != "" && !d.HasChange("gateway_id") && !d.HasChange(i.Key) {
+			return nil
+		}
+		if !d.NewValueKnown("gateway_id") || !d.NewValueKnown(i.Key) {
+			return nil
+		}
+		gatewayId, _ := d.Get("gateway_id").(string)
+		key := fmt.Sprint(d.Get(i.Key))
+		if gatewayId == "" || key == "" {
+			return nil
+		}
+
+		gateway, resp, err := m.(*apiClient).EdgesApi.GetEdgeById(ctx, gatewayId, nil)
+		if err != nil {
+			if m.(*apiClient).isNotFound(err, resp, "/edges/"+gatewayId) {
+				return nil
+			}
+			return fmt.Errorf("GetEdgeById: %w", err)
+		}
+
+		for _, k := range i.Keys(&gateway) {
+			if k == key {
+				return fmt.Errorf("%s %q already exists on gateway %s: import it, or declare it in %s "+
+					"if that resource manages the %s of the gateway", i.What, key, gatewayId, i.Owner, i.List)
+			}
+		}
+
+		return nil
+	}
+}
+
+// uniqueKeys is a CustomizeDiff rejecting blocks of the list or set that
+// share the same values for all of the given keys.
+func uniqueKeys(list string, keys ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		seen := map[string]bool{}
+		for _, values := range configKeyValues(d, list, keys) {
+			// Unknown values are not known to collide yet.
+			if values == nil {
+				continue
+			}
+
+			k := strings.Join(values, "/")
+			if seen[k] {
+				return fmt.Errorf("%s blocks must have a unique %s, %q is used more than once",
+					list, strings.Join(keys, "/"), k)
+			}
+			seen[k] = true
+		}
+		return nil
+	}
+}
+
+// configKeyValues returns the values of the keys of each configured block of
+// the list or set, nil for blocks where any of them is not known yet. The raw
+// configuration is used when available, since set blocks sharing a hash
+// would otherwise have been merged already.
+func configKeyValues(d *schema.ResourceDiff, list string, keys []string) [][]string {
+	var items [][]string
+
+	if raw := d.GetRawConfig(); !raw.IsNull() {
+		v := raw.GetAttr(list)
+		if !v.IsKnown() || v.IsNull() {
+			return nil
+		}
+		for it := v.ElementIterator(); it.Next(); {
+			_, item := it.Element()
+			items = append(items, ctyKeyValues(item, keys))
+		}
+		return items
+	}
+
+	switch l := d.Get(list).(type) {
+	case []interface{}:
+		for i, v := range l {
+			item, _ := v.(map[string]interface{})
+			var values []string
+			for _, key := range keys {
+				if !d.NewValueKnown(fmt.Sprintf("%s.%d.%s", list, i, key)) {
+					values = nil
+					break
+				}
+				values = append(values, fmt.Sprint(item[key]))
+			}
+			items = append(items, values)
+		}
+	case *schema.Set:
+		for _, v := range l.List() {
+			item, _ := v.(map[string]interface{})
+			var values []string
+			for _, key := range keys {
+				values = append(values, fmt.Sprint(item[key]))
+			}
+			items = append(items, values)
+		}
+	}
+
+	return items
+}
+
+// ctyKeyValues returns the values of the keys of a configuration block as
+// strings, or nil if any of them is not known yet.
+func ctyKeyValues(item cty.Value, keys []string) []string {
+	if !item.IsKnown() || item.IsNull() {
+		return nil
+	}
+
+	var values []string
+	for _, key := range keys {
+		v := item.GetAttr(key)
+		switch {
+		case !v.IsKnown():
+			return nil
+		case v.IsNull():
+			values = append(values, "")
+		case v.Type() == cty.String:
+			values = append(values, v.AsString())
+		case v.Type() == cty.Number:
+			values = append(values, v.AsBigFloat().Text('f', -1))
+		case v.Type() == cty.Bool:
+			values = append(values, fmt.Sprint(v.True()))
+		default:
+			values = append(values, v.GoString())
+		}
+	}
+
+	return values
+}
+
+// orderByKey returns the items in the order of prior, the list known to
+// Terraform, followed by the items missing from prior in the order of the
+// API. This keeps the API from reordering a list into a spurious diff,
+// while items added elsewhere are kept so that they are planned for removal.
+func orderByKey[T any](prior, items []T, key func(T) string) []T {
+	index := map[string]int{}
+	for i, item := range prior {
+		index[key(item)] = i
+	}
+	position := func(item T) int {
+		if i, ok := index[key(item)]; ok {
+			return i
+		}
+		return len(prior)
+	}
+
+	ordered := append([]T{}, items...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return position(ordered[i]) < position(ordered[j])
+	})
+
+	return ordered
+}
+
+// itemKeys returns the keys of the items.
+func itemKeys[T any](items []T, key func(T) string) []string {
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, key(item))
+	}
+
+	return keys
+}
@@ -0,0 +1,111 @@
+package bwan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+)
+
+// apiClient is the provider meta handed to every resource and data source.
+// It embeds the generated client so that API services are reachable as
+// before, and keeps the state shared by the resources of one provider
+// instance.
+type apiClient struct {
+	*swagger.APIClient
+	cfg *swagger.Configuration
+
+	writer *edgeWriter
+}
+
+func newAPIClient(cfg *swagger.Configuration) *apiClient {
+	c := &apiClient{
+		APIClient: swagger.NewAPIClient(cfg),
+		cfg:       cfg,
+	}
+	c.writer = newEdgeWriter(c, edgeWriteWindow)
+
+	return c
+}
+
+// apiStatusError is returned by requests made outside of the generated
+// client. Like swagger.GenericSwaggerError it carries the status and body.
+type apiStatusError struct {
+	status string
+	body   []byte
+}
+
+func (e apiStatusError) Error() string {
+	return e.status
+}
+
+func (e apiStatusError) Body() []byte {
+	return e.body
+}
+
+// edgeVersion is the version of an edge an update is conditional on: its
+// ETag, or where the API does not send one, its modification date.
+type edgeVersion struct {
+	etag     string
+	modified time.Time
+}
+
+// putEdge sends an update of the edge with the given body, bypassing the
+// generated client for bodies swagger.UpdateEdgeInput cannot express, such as
+// empty lists. The update is conditional on the version, sent as If-Match or
+// If-Unmodified-Since, unless the version is zero.
+func (c *apiClient) putEdge(ctx context.Context, id string, v interface{}, version edgeVersion) (swagger.Edge, *http.Response, error) {
+	var gateway swagger.Edge
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		return gateway, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut,
+		fmt.Sprintf("%s/edges/%s", c.cfg.BasePath, url.PathEscape(id)), bytes.NewReader(body))
+	if err != nil {
+		return gateway, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+	for k, v := range c.cfg.DefaultHeader {
+		req.Header.Set(k, v)
+	}
+	switch {
+	case version.etag != "":
+		req.Header.Set("If-Match", version.etag)
+	case !version.modified.IsZero():
+		req.Header.Set("If-Unmodified-Since", version.modified.UTC().Format(http.TimeFormat))
+	}
+
+	client := c.cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return gateway, resp, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return gateway, resp, err
+	}
+	if resp.StatusCode >= 300 {
+		return gateway, resp, apiStatusError{status: resp.Status, body: respBody}
+	}
+
+	return gateway, resp, json.Unmarshal(respBody, &gateway)
+}
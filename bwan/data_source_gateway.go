@@ -2,7 +2,7 @@ package bwan
 
 import (
 	"context"
-	"fmt"
+	"net/http"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 
@@ -22,32 +22,28 @@ func (rt _dataSourceGateway) dataSourceGatewayRead(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(gwInput.Id) > 0 {
-		gateway, _, err = apiSvc.EdgesApi.GetEdgeById(ctx, gwInput.Id, nil)
+		var resp *http.Response
+		gateway, resp, err = apiSvc.EdgesApi.GetEdgeById(ctx, gwInput.Id, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
+			return apiError("GetEdgeById", resp, err, rt.Binder)
 		}
 	} else if len(gwInput.Name) > 0 {
-		gatewayList, _, err := apiSvc.EdgesApi.GetAllEdges(ctx, nil)
-		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
-		}
-		for _, gw := range gatewayList.Data {
+		// The API cannot filter edges by name, stop paging at the first match.
+		resp, err := forEachEdge(ctx, apiSvc, func(gw swagger.Edge) bool {
 			if gw.Name == gwInput.Name {
 				gateway = gw
-				break
+				return false
 			}
+			return true
+		})
+		if err != nil {
+			return apiError("GetAllEdges", resp, err, rt.Binder)
 		}
 		if len(gateway.Name) == 0 {
-			return diag.FromErr(err)
+			return diag.Errorf("gateway %q not found", gwInput.Name)
 		}
 	}
 	err = ApplyBinderResourceData(rt.Binder, d, gateway)
@@ -65,12 +61,18 @@ type _dataSourceGateway struct {
 	InputBinder []FieldBinder
 }
 
-func dataSourceGateway() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(swagger.Edge{}, Cfg{})
+func dataSourceGateway() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(swagger.Edge{}, Cfg{
+		// Looked up by ID or name.
+		"id": {Schema: schema.Schema{Optional: true, Computed: true}},
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _dataSourceGateway{Binder: binder, InputBinder: inputBinder}
 	return &schema.Resource{
 		ReadContext: rt.dataSourceGatewayRead,
 		Schema:      swaggerSchema,
-	}
+	}, nil
 }
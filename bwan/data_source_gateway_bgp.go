@@ -2,7 +2,6 @@ package bwan
 
 import (
 	"context"
-	"fmt"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 
@@ -34,15 +33,12 @@ func (rt _dataSourceGatewayBgp) dataSourceGatewayBgpRead(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(bgpInput.GatewayId) > 0 {
-		gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, bgpInput.GatewayId, nil)
+		gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, bgpInput.GatewayId, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
+			return apiError("GetEdgeById", resp, err, rt.Binder)
 		}
 		index := rt.getExistingBgpPeer(gateway.BgpConfiguration, bgpInput.EdgeBgpConfiguration)
 		if index >= 0 {
@@ -72,16 +68,19 @@ type dataSourceGatewayBgpInput struct {
 	swagger.EdgeBgpConfiguration
 }
 
-func dataSourceGatewayBgp() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(dataSourceGatewayBgpInput{}, Cfg{
+func dataSourceGatewayBgp() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(dataSourceGatewayBgpInput{}, Cfg{
 		"name":       {Schema: schema.Schema{Required: true}},
 		"gateway_id": {Schema: schema.Schema{Required: true}},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _dataSourceGatewayBgp{Binder: binder, InputBinder: inputBinder}
 
 	return &schema.Resource{
 		ReadContext: rt.dataSourceGatewayBgpRead,
 		Schema:      swaggerSchema,
-	}
+	}, nil
 }
@@ -0,0 +1,356 @@
+package bwan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/go-cty/cty"
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func (rt _dataSourceGatewayBootstrap) dataSourceGatewayBootstrapRead(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	apiSvc := m.(*apiClient)
+
+	bootstrapInput, err := ApplyBinderInputResourceData[dataSourceGatewayBootstrapInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, bootstrapInput.GatewayId, nil)
+	if err != nil {
+		return apiError("GetEdgeById", resp, err, rt.Binder)
+	}
+
+	data := bootstrapData(gateway, bootstrapInput.OrchestratorUrl, bootstrapInput.Token)
+	bootstrapInput.CloudInit = renderCloudInit(data)
+	bootstrapInput.Env = renderEnv(data)
+	if bootstrapInput.Template != "" {
+		bootstrapInput.Rendered, err = renderBootstrap(bootstrapInput.Template, data)
+		if err != nil {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Rendering the bootstrap template failed",
+					Detail:        err.Error(),
+					AttributePath: cty.GetAttrPath("template"),
+				},
+			}
+		}
+	}
+
+	err = ApplyBinderResourceData(rt.Binder, d, bootstrapInput)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(gateway.Id)
+	return diags
+}
+
+// bootstrapGateway is the data of the bootstrap template.
+type bootstrapGateway struct {
+	GatewayId       string
+	GatewayName     string
+	OrchestratorUrl string
+	Token           string
+	// Interfaces are the enabled interfaces of the gateway, in name order.
+	Interfaces []bootstrapInterface
+}
+
+// bootstrapInterface describes an interface and its first IPv4 address.
+type bootstrapInterface struct {
+	Name              string
+	Type              string
+	Mode              string
+	Zone              string
+	AddressAssignment string
+	Address           string
+	Mask              string
+	Gateway           string
+	// Hint sums the above up, e.g. "ethernet, routed, untrusted, dhcp".
+	Hint string
+}
+
+// bootstrapData returns the template data of the gateway. It depends on its
+// arguments only, with the interfaces in name order, so that the rendered
+// template does not change between runs.
+func bootstrapData(gateway swagger.Edge, url, token string) bootstrapGateway {
+	interfaces := slices.Clone(gateway.Interfaces)
+	interfaces = slices.DeleteFunc(interfaces, func(i swagger.InterfaceSettings) bool {
+		return i.IsDisabled
+	})
+	slices.SortFunc(interfaces, func(a, b swagger.InterfaceSettings) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	data := bootstrapGateway{
+		GatewayId:       gateway.Id,
+		GatewayName:     gateway.Name,
+		OrchestratorUrl: url,
+		Token:           token,
+		Interfaces:      []bootstrapInterface{},
+	}
+	for _, i := range interfaces {
+		a := ipv4Address(i)
+		data.Interfaces = append(data.Interfaces, bootstrapInterface{
+			Name:              i.Name,
+			Type:              i.Type_,
+			Mode:              i.Mode,
+			Zone:              i.Zone,
+			AddressAssignment: a.AddressAssignment,
+			Address:           a.Address,
+			Mask:              a.Mask,
+			Gateway:           a.Gateway,
+			Hint:              strings.Join(interfaceHints(i), ", "),
+		})
+	}
+
+	return data
+}
+
+// renderCloudInit renders the cloud-init user data of the device: an
+// infiot section with the orchestrator URL and the activation token, and
+// the enabled interfaces listed in a comment as a hint for attaching the
+// network interfaces of the virtual machine.
+func renderCloudInit(data bootstrapGateway) string {
+	var c strings.Builder
+	c.WriteString("#cloud-config\n")
+	fmt.Fprintf(&c, "# Netskope BWAN gateway %s (%s)\n", yamlString(data.GatewayName), envValue(data.GatewayId))
+	if len(data.Interfaces) > 0 {
+		c.WriteString("#\n# Interfaces:\n")
+		for _, i := range data.Interfaces {
+			fmt.Fprintf(&c, "#   %s: %s\n", envValue(i.Name), envValue(i.Hint))
+		}
+	}
+	c.WriteString("infiot:\n")
+	fmt.Fprintf(&c, "  uri: %s\n", yamlString(data.OrchestratorUrl))
+	fmt.Fprintf(&c, "  token: %s\n", yamlString(data.Token))
+
+	return c.String()
+}
+
+// renderEnv renders the same settings as a KEY=value env file, with the
+// settings of each enabled interface in NS_BWAN_INTERFACE_<NAME>_*
+// variables. Empty settings are left out.
+func renderEnv(data bootstrapGateway) string {
+	var e strings.Builder
+	fmt.Fprintf(&e, "NS_BWAN_GATEWAY_ID=%s\n", envValue(data.GatewayId))
+	fmt.Fprintf(&e, "NS_BWAN_GATEWAY_NAME=%s\n", envValue(data.GatewayName))
+	fmt.Fprintf(&e, "NS_BWAN_ORCHESTRATOR_URL=%s\n", envValue(data.OrchestratorUrl))
+	fmt.Fprintf(&e, "NS_BWAN_TOKEN=%s\n", envValue(data.Token))
+
+	names := make([]string, len(data.Interfaces))
+	for n, i := range data.Interfaces {
+		names[n] = i.Name
+	}
+	fmt.Fprintf(&e, "NS_BWAN_INTERFACES=%s\n", envValue(strings.Join(names, ",")))
+
+	for _, i := range data.Interfaces {
+		prefix := "NS_BWAN_INTERFACE_" + envKey(i.Name) + "_"
+		for _, kv := range [][2]string{
+			{"TYPE", i.Type},
+			{"MODE", i.Mode},
+			{"ZONE", i.Zone},
+			{"ADDRESS_ASSIGNMENT", i.AddressAssignment},
+			{"ADDRESS", i.Address},
+			{"MASK", i.Mask},
+			{"GATEWAY", i.Gateway},
+		} {
+			if kv[1] != "" {
+				fmt.Fprintf(&e, "%s%s=%s\n", prefix, kv[0], envValue(kv[1]))
+			}
+		}
+	}
+
+	return e.String()
+}
+
+// bootstrapFuncs are the functions available to bootstrap templates, for
+// the quoting of the formats they are likely to produce.
+var bootstrapFuncs = template.FuncMap{
+	"yaml":   yamlString,
+	"line":   envValue,
+	"envkey": envKey,
+}
+
+func parseBootstrapTemplate(text string) (*template.Template, error) {
+	return template.New("template").Funcs(bootstrapFuncs).Option("missingkey=error").Parse(text)
+}
+
+// renderBootstrap renders the template supplied by the caller, for images
+// that take another bootstrap format than renderCloudInit and renderEnv.
+func renderBootstrap(text string, data bootstrapGateway) (string, error) {
+	tmpl, err := parseBootstrapTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// validateBootstrapTemplate rejects templates that do not parse.
+func validateBootstrapTemplate(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+
+	if _, err := parseBootstrapTemplate(v); err != nil {
+		return nil, []error{fmt.Errorf("expected %s to be a valid template: %w", k, err)}
+	}
+
+	return nil, nil
+}
+
+// interfaceHints describes how the interface is set up, e.g. "ethernet,
+// routed, untrusted, static 10.0.0.1/24 via 10.0.0.254".
+func interfaceHints(i swagger.InterfaceSettings) []string {
+	var hints []string
+	for _, h := range []string{i.Type_, i.Mode, i.Zone} {
+		if h != "" {
+			hints = append(hints, h)
+		}
+	}
+
+	a := ipv4Address(i)
+	switch {
+	case a.AddressAssignment == "static" && a.Address != "":
+		h := "static " + a.Address
+		if a.Mask != "" {
+			h += "/" + a.Mask
+		}
+		if a.Gateway != "" {
+			h += " via " + a.Gateway
+		}
+		hints = append(hints, h)
+	case a.AddressAssignment != "":
+		hints = append(hints, a.AddressAssignment)
+	}
+
+	return hints
+}
+
+// ipv4Address returns the first IPv4 address of the interface.
+func ipv4Address(i swagger.InterfaceSettings) swagger.InterfaceSettingsAddresses {
+	for _, a := range i.Addresses {
+		if a.AddressFamily == "" || a.AddressFamily == "ipv4" {
+			return a
+		}
+	}
+
+	return swagger.InterfaceSettingsAddresses{}
+}
+
+var matchEnvKeyInvalid = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// envKey turns an interface name such as "GE1" or "lte-0" into the part of
+// an environment variable name, e.g. "GE1" or "LTE_0".
+func envKey(name string) string {
+	return matchEnvKeyInvalid.ReplaceAllString(strings.ToUpper(name), "_")
+}
+
+// envValue keeps s on a single line, for formats such as env files and
+// comments that have no quoting.
+func envValue(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// yamlString quotes s as a YAML double-quoted scalar, whose escapes are a
+// superset of JSON's.
+func yamlString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+type _dataSourceGatewayBootstrap struct {
+	Binder      []FieldBinder
+	InputBinder []FieldBinder
+}
+
+type dataSourceGatewayBootstrapInput struct {
+	GatewayId       string
+	Token           string
+	OrchestratorUrl string
+	Template        string
+	CloudInit       string
+	Env             string
+	Rendered        string
+}
+
+func dataSourceGatewayBootstrap() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(dataSourceGatewayBootstrapInput{}, Cfg{
+		"gateway_id": {Schema: schema.Schema{Required: true}},
+		"token": {
+			Schema: schema.Schema{
+				Required:    true,
+				Sensitive:   true,
+				Description: "Activation token of the gateway, e.g. the `token` of a `netskopebwan_gateway_activate`.",
+			},
+		},
+		"orchestrator_url": {
+			Schema: schema.Schema{
+				Required: true,
+				Description: "URL of the orchestrator the device activates with, as given by the deployment guide " +
+					"of the tenant. This is not the `baseurl` of the provider, which is the management API.",
+			},
+		},
+		"template": {
+			Schema: schema.Schema{
+				Optional: true,
+				Description: "Bootstrap file as a Go template, for device images that take another format than " +
+					"`cloud_init` and `env`. It is rendered into `rendered`.",
+			},
+			Validate: validateBootstrapTemplate,
+		},
+		"cloud_init": {
+			Schema: schema.Schema{
+				Computed:  true,
+				Sensitive: true,
+				Description: "Cloud-init user data activating the device with the token. The enabled interfaces " +
+					"of the gateway are listed in a comment as a hint for attaching the network interfaces " +
+					"of the virtual machine.",
+			},
+		},
+		"env": {
+			Schema: schema.Schema{
+				Computed:  true,
+				Sensitive: true,
+				Description: "The same settings as a `KEY=value` env file, with the type, mode, zone and " +
+					"IPv4 address of each enabled interface in `NS_BWAN_INTERFACE_<NAME>_*` variables.",
+			},
+		},
+		"rendered": {
+			Schema: schema.Schema{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The rendered `template`, empty without one.",
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rt := _dataSourceGatewayBootstrap{Binder: binder, InputBinder: inputBinder}
+
+	return &schema.Resource{
+		ReadContext: rt.dataSourceGatewayBootstrapRead,
+		Schema:      swaggerSchema,
+	}, nil
+}
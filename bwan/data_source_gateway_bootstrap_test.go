@@ -0,0 +1,194 @@
+package bwan
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBootstrapEdge() swagger.Edge {
+	return swagger.Edge{
+		Id:   "gw1",
+		Name: "hub-1",
+		Interfaces: []swagger.InterfaceSettings{
+			{
+				Name: "GE2", Type_: "ethernet", Mode: "routed", Zone: "trusted",
+				Addresses: []swagger.InterfaceSettingsAddresses{
+					{AddressFamily: "ipv6", AddressAssignment: "static", Address: "fd00::1"},
+					{AddressFamily: "ipv4", AddressAssignment: "static", Address: "10.0.0.1", Mask: "24", Gateway: "10.0.0.254"},
+				},
+			},
+			{Name: "GE3", Type_: "ethernet", IsDisabled: true},
+			{
+				Name: "GE1", Type_: "ethernet", Mode: "routed", Zone: "untrusted",
+				Addresses: []swagger.InterfaceSettingsAddresses{{AddressAssignment: "dhcp"}},
+			},
+			{Name: "lte-0", Type_: "lte"},
+		},
+	}
+}
+
+// testBootstrapTemplate renders both a cloud-init file and an env file, as
+// the deployment guide of an image might document them.
+const testBootstrapTemplate = `#cloud-config
+# {{ line .GatewayName }} ({{ .GatewayId }})
+{{- range .Interfaces }}
+#   {{ line .Name }}: {{ line .Hint }}
+{{- end }}
+bootstrap:
+  url: {{ yaml .OrchestratorUrl }}
+  token: {{ yaml .Token }}
+---
+TOKEN={{ line .Token }}
+{{- range .Interfaces }}
+{{- if .Address }}
+{{ envkey .Name }}_ADDRESS={{ .Address }}/{{ .Mask }}
+{{- end }}
+{{- end }}
+`
+
+func TestRenderCloudInit(t *testing.T) {
+	data := bootstrapData(testBootstrapEdge(), "https://acme.infiot.net", "token")
+	cloudInit := renderCloudInit(data)
+	assert.Equal(t, `#cloud-config
+# Netskope BWAN gateway "hub-1" (gw1)
+#
+# Interfaces:
+#   GE1: ethernet, routed, untrusted, dhcp
+#   GE2: ethernet, routed, trusted, static 10.0.0.1/24 via 10.0.0.254
+#   lte-0: lte
+infiot:
+  uri: "https://acme.infiot.net"
+  token: "token"
+`, cloudInit)
+
+	cloudInit = renderCloudInit(bootstrapData(swagger.Edge{Id: "gw2", Name: "spoke"}, "https://acme.infiot.net", "token"))
+	assert.Equal(t, `#cloud-config
+# Netskope BWAN gateway "spoke" (gw2)
+infiot:
+  uri: "https://acme.infiot.net"
+  token: "token"
+`, cloudInit)
+}
+
+func TestRenderEnv(t *testing.T) {
+	data := bootstrapData(testBootstrapEdge(), "https://acme.infiot.net", "token")
+	assert.Equal(t, `NS_BWAN_GATEWAY_ID=gw1
+NS_BWAN_GATEWAY_NAME=hub-1
+NS_BWAN_ORCHESTRATOR_URL=https://acme.infiot.net
+NS_BWAN_TOKEN=token
+NS_BWAN_INTERFACES=GE1,GE2,lte-0
+NS_BWAN_INTERFACE_GE1_TYPE=ethernet
+NS_BWAN_INTERFACE_GE1_MODE=routed
+NS_BWAN_INTERFACE_GE1_ZONE=untrusted
+NS_BWAN_INTERFACE_GE1_ADDRESS_ASSIGNMENT=dhcp
+NS_BWAN_INTERFACE_GE2_TYPE=ethernet
+NS_BWAN_INTERFACE_GE2_MODE=routed
+NS_BWAN_INTERFACE_GE2_ZONE=trusted
+NS_BWAN_INTERFACE_GE2_ADDRESS_ASSIGNMENT=static
+NS_BWAN_INTERFACE_GE2_ADDRESS=10.0.0.1
+NS_BWAN_INTERFACE_GE2_MASK=24
+NS_BWAN_INTERFACE_GE2_GATEWAY=10.0.0.254
+NS_BWAN_INTERFACE_LTE_0_TYPE=lte
+`, renderEnv(data))
+}
+
+func TestRenderBootstrap(t *testing.T) {
+	data := bootstrapData(testBootstrapEdge(), "https://acme.infiot.net", "tok:en")
+	rendered, err := renderBootstrap(testBootstrapTemplate, data)
+	require.NoError(t, err)
+
+	assert.Equal(t, `#cloud-config
+# hub-1 (gw1)
+#   GE1: ethernet, routed, untrusted, dhcp
+#   GE2: ethernet, routed, trusted, static 10.0.0.1/24 via 10.0.0.254
+#   lte-0: lte
+bootstrap:
+  url: "https://acme.infiot.net"
+  token: "tok:en"
+---
+TOKEN=tok:en
+GE2_ADDRESS=10.0.0.1/24
+`, rendered)
+
+	assert.Equal(t, bootstrapInterface{
+		Name: "GE2", Type: "ethernet", Mode: "routed", Zone: "trusted",
+		AddressAssignment: "static", Address: "10.0.0.1", Mask: "24", Gateway: "10.0.0.254",
+		Hint: "ethernet, routed, trusted, static 10.0.0.1/24 via 10.0.0.254",
+	}, data.Interfaces[1])
+
+	// The interfaces of the orchestrator come in no particular order.
+	edge := testBootstrapEdge()
+	edge.Interfaces[0], edge.Interfaces[3] = edge.Interfaces[3], edge.Interfaces[0]
+	again, err := renderBootstrap(testBootstrapTemplate, bootstrapData(edge, "https://acme.infiot.net", "tok:en"))
+	require.NoError(t, err)
+	assert.Equal(t, rendered, again)
+
+	_, err = renderBootstrap("{{ .Tokn }}", data)
+	assert.ErrorContains(t, err, `can't evaluate field Tokn`)
+}
+
+func TestRenderBootstrapQuoting(t *testing.T) {
+	edge := swagger.Edge{Id: "gw1", Name: "hub \"1\"\n# injected: true"}
+	rendered, err := renderBootstrap("# {{ line .GatewayName }}\nbootstrap:\n  token: {{ yaml .Token }}\n",
+		bootstrapData(edge, "https://acme.infiot.net", "a\"b\nc: d"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "# hub \"1\" # injected: true\nbootstrap:\n  token: \"a\\\"b\\nc: d\"\n", rendered)
+
+	// YAML double-quoted scalars are a superset of JSON strings.
+	var token string
+	_, quoted, _ := strings.Cut(rendered, "token: ")
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(quoted)), &token))
+	assert.Equal(t, "a\"b\nc: d", token)
+}
+
+func TestDataSourceGatewayBootstrap(t *testing.T) {
+	_, client := newFakeEdgeAPI(t, testBootstrapEdge())
+	ds := mustResource(t, dataSourceGatewayBootstrap)
+
+	d := schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"gateway_id":       "gw1",
+		"token":            "token",
+		"orchestrator_url": "https://acme.infiot.net",
+		"template":         testBootstrapTemplate,
+	})
+	diags := ds.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, "gw1", d.Id())
+	assert.Contains(t, d.Get("rendered"), "  url: \"https://acme.infiot.net\"\n  token: \"token\"\n")
+	assert.Contains(t, d.Get("cloud_init"), "infiot:\n  uri: \"https://acme.infiot.net\"\n  token: \"token\"\n")
+	assert.Contains(t, d.Get("env"), "NS_BWAN_TOKEN=token\n")
+
+	d = schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"gateway_id":       "gw1",
+		"token":            "token",
+		"orchestrator_url": "https://acme.infiot.net",
+	})
+	diags = ds.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, renderCloudInit(bootstrapData(testBootstrapEdge(), "https://acme.infiot.net", "token")), d.Get("cloud_init"))
+	assert.Equal(t, "", d.Get("rendered"))
+
+	d = schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"gateway_id":       "gw1",
+		"token":            "token",
+		"orchestrator_url": "https://acme.infiot.net",
+		"template":         "{{ .Interfaces.Name }}",
+	})
+	diags = ds.ReadContext(context.Background(), d, client)
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Rendering the bootstrap template failed", diags[0].Summary)
+
+	_, errs := validateBootstrapTemplate("{{ .Token", "template")
+	assert.Len(t, errs, 1)
+	_, errs = validateBootstrapTemplate("{{ upper .Token }}", "template")
+	assert.Len(t, errs, 1)
+}
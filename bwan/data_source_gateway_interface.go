@@ -2,7 +2,7 @@ package bwan
 
 import (
 	"context"
-	"fmt"
+	"net/http"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 
@@ -23,15 +23,13 @@ func (rt _dataSourceGatewayInterface) dataSourceGatewayInterfaceRead(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(intfInput.GatewayId) > 0 && len(intfInput.Name) > 0 {
-		intf, _, err = apiSvc.EdgesApi.GetEdgeIfByName(ctx, intfInput.GatewayId, intfInput.Name, nil)
+		var resp *http.Response
+		intf, resp, err = apiSvc.EdgesApi.GetEdgeIfByName(ctx, intfInput.GatewayId, intfInput.Name, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
+			return apiError("GetEdgeIfByName", resp, err, rt.Binder)
 		}
 	} else {
 		return diag.FromErr(err)
@@ -58,16 +56,19 @@ type dataSourceGatewayInterfaceInput struct {
 	swagger.InterfaceSettings
 }
 
-func dataSourceGatewayInterface() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(dataSourceGatewayInterfaceInput{}, Cfg{
+func dataSourceGatewayInterface() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(dataSourceGatewayInterfaceInput{}, Cfg{
 		"name":       {Schema: schema.Schema{Required: true}},
 		"gateway_id": {Schema: schema.Schema{Required: true}},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _dataSourceGatewayInterface{Binder: binder, InputBinder: inputBinder}
 
 	return &schema.Resource{
 		ReadContext: rt.dataSourceGatewayInterfaceRead,
 		Schema:      swaggerSchema,
-	}
+	}, nil
 }
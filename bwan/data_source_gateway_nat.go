@@ -2,7 +2,6 @@ package bwan
 
 import (
 	"context"
-	"fmt"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
@@ -24,15 +23,12 @@ func (rt _dataSourceGatewayNat) dataSourceGatewayNatRead(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(edgeInput.GatewayId) > 0 {
-		gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
+		gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
+			return apiError("GetEdgeById", resp, err, rt.Binder)
 		}
 
 		natConfig = rt.GetConfig(&gateway, edgeInput)
@@ -60,10 +56,13 @@ type dataSourceGatewayNatInput struct {
 	swagger.InboundNatRule
 }
 
-func dataSourceGatewayNat() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(dataSourceGatewayNatInput{}, Cfg{
+func dataSourceGatewayNat() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(dataSourceGatewayNatInput{}, Cfg{
 		"gateway_id": {Schema: schema.Schema{Required: true}},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _dataSourceGatewayNat{Binder: binder,
 		InputBinder: inputBinder,
@@ -81,14 +80,17 @@ func dataSourceGatewayNat() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: rt.dataSourceGatewayNatRead,
 		Schema:      swaggerSchema,
-	}
+	}, nil
 }
 
-func dataSourceGatewayPortForward() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(dataSourceGatewayNatInput{}, Cfg{
+func dataSourceGatewayPortForward() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(dataSourceGatewayNatInput{}, Cfg{
 		"gateway_id": {Schema: schema.Schema{Required: true}},
 		"name":       {Schema: schema.Schema{Required: true}},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _dataSourceGatewayNat{Binder: binder,
 		InputBinder: inputBinder,
@@ -106,5 +108,5 @@ func dataSourceGatewayPortForward() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: rt.dataSourceGatewayNatRead,
 		Schema:      swaggerSchema,
-	}
+	}, nil
 }
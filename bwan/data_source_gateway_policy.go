@@ -2,7 +2,7 @@ package bwan
 
 import (
 	"context"
-	"fmt"
+	"net/http"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 
@@ -22,23 +22,18 @@ func (rt _dataSourcePolicy) dataSourcePolicyRead(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(policyInput.Id) > 0 {
-		policy, _, err = apiSvc.PoliciesApi.GetPolicyById(ctx, policyInput.Id, nil)
+		var resp *http.Response
+		policy, resp, err = apiSvc.PoliciesApi.GetPolicyById(ctx, policyInput.Id, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
+			return apiError("GetPolicyById", resp, err, rt.Binder)
 		}
 	} else if len(policyInput.Name) > 0 {
-		policyList, _, err := apiSvc.PoliciesApi.GetAllPolicies(ctx, nil)
+		policyList, resp, err := apiSvc.PoliciesApi.GetAllPolicies(ctx, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
+			return apiError("GetAllPolicies", resp, err, rt.Binder)
 		}
 		for _, pol := range policyList {
 			if pol.Name == policyInput.Name {
@@ -68,15 +63,20 @@ type _dataSourcePolicy struct {
 	InputBinder []FieldBinder
 }
 
-func dataSourcePolicy() *schema.Resource {
-	swaggerSchema, binder, swaggerInputBinder := ReflectSchema(swagger.Policy{}, Cfg{
+func dataSourcePolicy() (*schema.Resource, error) {
+	swaggerSchema, binder, swaggerInputBinder, err := ReflectSchema(swagger.Policy{}, Cfg{
+		// Looked up by ID or name.
+		"id":   {Schema: schema.Schema{Optional: true, Computed: true}},
 		"name": {Schema: schema.Schema{Required: true}},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _dataSourcePolicy{Binder: binder, InputBinder: swaggerInputBinder}
 
 	return &schema.Resource{
 		ReadContext: rt.dataSourcePolicyRead,
 		Schema:      swaggerSchema,
-	}
+	}, nil
 }
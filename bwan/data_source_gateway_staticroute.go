@@ -2,7 +2,6 @@ package bwan
 
 import (
 	"context"
-	"fmt"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 
@@ -32,15 +31,12 @@ func (rt _dataSourceGatewayStaticRoute) dataSourceGatewayStaticRouteRead(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(edgeInput.GatewayId) > 0 {
-		gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
+		gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
+			return apiError("GetEdgeById", resp, err, rt.Binder)
 		}
 		index := rt.getExistingStaticRoute(gateway.StaticRoutes, edgeInput.StaticRoute)
 		if index >= 0 {
@@ -70,17 +66,20 @@ type dataSourceGatewayStaticRouteInput struct {
 	swagger.StaticRoute
 }
 
-func dataSourceGatewayStaticRoute() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(
+func dataSourceGatewayStaticRoute() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(
 		dataSourceGatewayStaticRouteInput{}, Cfg{
 			"gateway_id":  {Schema: schema.Schema{Required: true}},
 			"destination": {Schema: schema.Schema{Required: true}},
 		})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _dataSourceGatewayStaticRoute{Binder: binder, InputBinder: inputBinder}
 
 	return &schema.Resource{
 		ReadContext: rt.dataSourceGatewayStaticRouteRead,
 		Schema:      swaggerSchema,
-	}
+	}, nil
 }
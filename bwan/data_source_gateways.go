@@ -0,0 +1,139 @@
+package bwan
+
+import (
+	"context"
+	"regexp"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+type gatewayFilter struct {
+	Name           *regexp.Regexp
+	Role           string
+	Model          string
+	AssignedPolicy string
+	Activated      *bool
+}
+
+func (f gatewayFilter) match(gw swagger.Edge) bool {
+	if f.Name != nil && !f.Name.MatchString(gw.Name) {
+		return false
+	}
+	if f.Role != "" && (gw.Role == nil || string(*gw.Role) != f.Role) {
+		return false
+	}
+	if f.Model != "" && (gw.Model == nil || string(*gw.Model) != f.Model) {
+		return false
+	}
+	if f.AssignedPolicy != "" && (gw.AssignedPolicy == nil ||
+		(gw.AssignedPolicy.Name != f.AssignedPolicy && gw.AssignedPolicy.Id != f.AssignedPolicy)) {
+		return false
+	}
+	if f.Activated != nil && gw.Activated != *f.Activated {
+		return false
+	}
+
+	return true
+}
+
+func (rt _dataSourceGateways) dataSourceGatewaysRead(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+
+	var diags diag.Diagnostics
+	var err error
+
+	filter := gatewayFilter{
+		Role:           d.Get("role").(string),
+		Model:          d.Get("model").(string),
+		AssignedPolicy: d.Get("assigned_policy").(string),
+	}
+	if filter.Name, err = nameRegex(d); err != nil {
+		return diag.FromErr(err)
+	}
+	if activated, ok := optionalBool(d, "activated"); ok {
+		filter.Activated = &activated
+	}
+
+	apiSvc := m.(*apiClient)
+
+	var output dataSourceGatewaysOutput
+	ids := []string{}
+	resp, err := forEachEdge(ctx, apiSvc, func(gw swagger.Edge) bool {
+		if filter.match(gw) {
+			output.Gateways = append(output.Gateways, gw)
+			ids = append(ids, gw.Id)
+		}
+		return true
+	})
+	if err != nil {
+		return apiError("GetAllEdges", resp, err, rt.Binder)
+	}
+
+	err = ApplyBinderResourceData(rt.Binder, d, output)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(utils.Hash(ids))
+	return diags
+}
+
+type _dataSourceGateways struct {
+	Binder []FieldBinder
+}
+
+type dataSourceGatewaysOutput struct {
+	Gateways []swagger.Edge
+}
+
+func dataSourceGateways() (*schema.Resource, error) {
+	swaggerSchema, binder, _, err := ReflectSchema(dataSourceGatewaysOutput{}, Cfg{
+		"gateways": {Schema: schema.Schema{Computed: true}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	swaggerSchema["ids"] = idsSchema()
+	swaggerSchema["name_regex"] = nameRegexSchema()
+	swaggerSchema["role"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Only return gateways with this role: `hub`, `spoke` or `dcedge`.",
+		ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{
+			string(swagger.HUB_EdgeRole),
+			string(swagger.SPOKE_EdgeRole),
+			string(swagger.DCEDGE_EdgeRole),
+		}, false)),
+	}
+	swaggerSchema["model"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Only return gateways of this hardware model, e.g. `iXVirtual`.",
+	}
+	swaggerSchema["assigned_policy"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Only return gateways assigned to the policy with this name or ID.",
+	}
+	swaggerSchema["activated"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Only return activated (`true`) or not yet activated (`false`) gateways.",
+	}
+
+	rt := _dataSourceGateways{Binder: binder}
+
+	return &schema.Resource{
+		ReadContext: rt.dataSourceGatewaysRead,
+		Schema:      swaggerSchema,
+	}, nil
+}
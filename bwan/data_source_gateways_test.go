@@ -0,0 +1,80 @@
+package bwan
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testEdges() []swagger.Edge {
+	hub, spoke := swagger.HUB_EdgeRole, swagger.SPOKE_EdgeRole
+	virtual, hw := swagger.I_X_VIRTUAL_EdgeModel, swagger.I_X1000_W_EdgeModel
+
+	return []swagger.Edge{
+		{Id: "1", Name: "hub-east", Role: &hub, Model: &virtual, Activated: true,
+			AssignedPolicy: &swagger.PolicyRef{Id: "p1", Name: "hubs"}},
+		{Id: "2", Name: "hub-west", Role: &hub, Model: &hw,
+			AssignedPolicy: &swagger.PolicyRef{Id: "p1", Name: "hubs"}},
+		{Id: "3", Name: "branch-1", Role: &spoke, Model: &virtual, Activated: true},
+	}
+}
+
+func TestGatewayFilter(t *testing.T) {
+	activated, notActivated := true, false
+
+	tests := []struct {
+		name   string
+		filter gatewayFilter
+		ids    []string
+	}{
+		{"none", gatewayFilter{}, []string{"1", "2", "3"}},
+		{"role", gatewayFilter{Role: "hub"}, []string{"1", "2"}},
+		{"model", gatewayFilter{Model: "iXVirtual"}, []string{"1", "3"}},
+		{"policy name", gatewayFilter{AssignedPolicy: "hubs"}, []string{"1", "2"}},
+		{"policy id", gatewayFilter{AssignedPolicy: "p1"}, []string{"1", "2"}},
+		{"activated", gatewayFilter{Activated: &activated}, []string{"1", "3"}},
+		{"not activated", gatewayFilter{Activated: &notActivated}, []string{"2"}},
+		{"combined", gatewayFilter{Role: "hub", Activated: &activated}, []string{"1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ids []string
+			for _, gw := range testEdges() {
+				if test.filter.match(gw) {
+					ids = append(ids, gw.Id)
+				}
+			}
+			assert.Equal(t, test.ids, ids)
+		})
+	}
+}
+
+func TestDataSourceGatewaysRead(t *testing.T) {
+	client := testAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(swagger.EdgesList{LastPage: true, Data: testEdges()})
+	}))
+
+	ds := mustResource(t, dataSourceGateways)
+	d := schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"name_regex": "^hub-",
+		"activated":  false,
+	})
+
+	diags := ds.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, []interface{}{"2"}, d.Get("ids"))
+	assert.Equal(t, "hub-west", d.Get("gateways.0.name"))
+	policy := d.Get("gateways.0.assigned_policy").(*schema.Set).List()
+	require.Len(t, policy, 1)
+	assert.Equal(t, "hubs", policy[0].(map[string]interface{})["name"])
+	assert.NotEmpty(t, d.Id())
+}
@@ -0,0 +1,106 @@
+package bwan
+
+import (
+	"context"
+	"regexp"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+type policyFilter struct {
+	Name  *regexp.Regexp
+	Type_ string
+}
+
+func (f policyFilter) match(p swagger.Policy) bool {
+	if f.Name != nil && !f.Name.MatchString(p.Name) {
+		return false
+	}
+	if f.Type_ != "" && (p.Type_ == nil || string(*p.Type_) != f.Type_) {
+		return false
+	}
+
+	return true
+}
+
+func (rt _dataSourcePolicies) dataSourcePoliciesRead(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+
+	var diags diag.Diagnostics
+	var err error
+
+	filter := policyFilter{
+		Type_: d.Get("type").(string),
+	}
+	if filter.Name, err = nameRegex(d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	apiSvc := m.(*apiClient)
+
+	policyList, resp, err := apiSvc.PoliciesApi.GetAllPolicies(ctx, nil)
+	if err != nil {
+		return apiError("GetAllPolicies", resp, err, rt.Binder)
+	}
+
+	var output dataSourcePoliciesOutput
+	ids := []string{}
+	for _, p := range policyList {
+		if filter.match(p) {
+			output.Policies = append(output.Policies, p)
+			ids = append(ids, p.Id)
+		}
+	}
+
+	err = ApplyBinderResourceData(rt.Binder, d, output)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(utils.Hash(ids))
+	return diags
+}
+
+type _dataSourcePolicies struct {
+	Binder []FieldBinder
+}
+
+type dataSourcePoliciesOutput struct {
+	Policies []swagger.Policy
+}
+
+func dataSourcePolicies() (*schema.Resource, error) {
+	swaggerSchema, binder, _, err := ReflectSchema(dataSourcePoliciesOutput{}, Cfg{
+		"policies": {Schema: schema.Schema{Computed: true}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	swaggerSchema["ids"] = idsSchema()
+	swaggerSchema["name_regex"] = nameRegexSchema()
+	swaggerSchema["type"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Only return policies of this type: `gateway` or `client`.",
+		ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{
+			string(swagger.GATEWAY_PolicyType),
+			string(swagger.CLIENT_PolicyType),
+		}, false)),
+	}
+
+	rt := _dataSourcePolicies{Binder: binder}
+
+	return &schema.Resource{
+		ReadContext: rt.dataSourcePoliciesRead,
+		Schema:      swaggerSchema,
+	}, nil
+}
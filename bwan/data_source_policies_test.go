@@ -0,0 +1,71 @@
+package bwan
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testPolicies() []swagger.Policy {
+	gateway, client := swagger.GATEWAY_PolicyType, swagger.CLIENT_PolicyType
+
+	return []swagger.Policy{
+		{Id: "p1", Name: "hubs", Type_: &gateway},
+		{Id: "p2", Name: "laptops", Type_: &client},
+		{Id: "p3", Name: "spokes", Type_: &gateway},
+		{Id: "p4", Name: "legacy"},
+	}
+}
+
+func TestPolicyFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter policyFilter
+		ids    []string
+	}{
+		{"none", policyFilter{}, []string{"p1", "p2", "p3", "p4"}},
+		{"gateway", policyFilter{Type_: "gateway"}, []string{"p1", "p3"}},
+		{"client", policyFilter{Type_: "client"}, []string{"p2"}},
+		{"name", policyFilter{Name: regexp.MustCompile("s$")}, []string{"p1", "p2", "p3"}},
+		{"combined", policyFilter{Name: regexp.MustCompile("^hub"), Type_: "gateway"}, []string{"p1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ids []string
+			for _, p := range testPolicies() {
+				if test.filter.match(p) {
+					ids = append(ids, p.Id)
+				}
+			}
+			assert.Equal(t, test.ids, ids)
+		})
+	}
+}
+
+func TestDataSourcePoliciesRead(t *testing.T) {
+	client := testAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(testPolicies())
+	}))
+
+	ds := mustResource(t, dataSourcePolicies)
+	d := schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"type":       "gateway",
+		"name_regex": "^spoke",
+	})
+
+	diags := ds.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, []interface{}{"p3"}, d.Get("ids"))
+	assert.Equal(t, "spokes", d.Get("policies.0.name"))
+	assert.NotEmpty(t, d.Id())
+}
@@ -0,0 +1,162 @@
+package bwan
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func (rt _dataSourceSoftwareVersions) dataSourceSoftwareVersionsRead(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	apiSvc := m.(*apiClient)
+
+	output, err := ApplyBinderInputResourceData[dataSourceSoftwareVersionsOutput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The orchestrator has no catalogue of the software, the versions are
+	// the ones assigned to its gateways.
+	byModel := map[string]map[string]bool{}
+	resp, err := forEachEdge(ctx, apiSvc, func(gw swagger.Edge) bool {
+		if gw.Swversion == "" || gw.Model == nil {
+			return true
+		}
+		if output.Model != "" && string(*gw.Model) != output.Model {
+			return true
+		}
+		if byModel[string(*gw.Model)] == nil {
+			byModel[string(*gw.Model)] = map[string]bool{}
+		}
+		byModel[string(*gw.Model)][gw.Swversion] = true
+		return true
+	})
+	if err != nil {
+		return apiError("GetAllEdges", resp, err, rt.Binder)
+	}
+
+	all := map[string]bool{}
+	output.Models = []softwareVersionsModel{}
+	for _, model := range slices.Sorted(maps.Keys(byModel)) {
+		versions := slices.SortedFunc(maps.Keys(byModel[model]), compareVersions)
+		output.Models = append(output.Models, softwareVersionsModel{Model: model, Versions: versions})
+		maps.Copy(all, byModel[model])
+	}
+	output.Versions = slices.SortedFunc(maps.Keys(all), compareVersions)
+
+	err = ApplyBinderResourceData(rt.Binder, d, output)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(utils.Hash(output.Models))
+	return diags
+}
+
+// compareVersions orders software versions such as "4.2.10.1000" after
+// "4.2.9.1500", comparing runs of digits by their value and anything else
+// as strings.
+func compareVersions(a, b string) int {
+	as, bs := versionParts(a), versionParts(b)
+	for n := range min(len(as), len(bs)) {
+		ai, aerr := strconv.ParseUint(as[n], 10, 64)
+		bi, berr := strconv.ParseUint(bs[n], 10, 64)
+		if aerr == nil && berr == nil {
+			if c := cmp.Compare(ai, bi); c != 0 {
+				return c
+			}
+			continue
+		}
+		if c := strings.Compare(as[n], bs[n]); c != 0 {
+			return c
+		}
+	}
+	if c := cmp.Compare(len(as), len(bs)); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a, b)
+}
+
+// versionParts splits the version into runs of digits and of anything else
+// but separators, e.g. "R4.2-beta1" into "R", "4", "2", "beta", "1".
+func versionParts(v string) []string {
+	var parts []string
+	for _, field := range strings.FieldsFunc(v, func(r rune) bool {
+		return strings.ContainsRune(".-_+", r)
+	}) {
+		start, digits := 0, false
+		for i, r := range field {
+			if i > 0 && unicode.IsDigit(r) != digits {
+				parts = append(parts, field[start:i])
+				start = i
+			}
+			digits = unicode.IsDigit(r)
+		}
+		parts = append(parts, field[start:])
+	}
+
+	return parts
+}
+
+type _dataSourceSoftwareVersions struct {
+	Binder      []FieldBinder
+	InputBinder []FieldBinder
+}
+
+type softwareVersionsModel struct {
+	Model    string
+	Versions []string
+}
+
+type dataSourceSoftwareVersionsOutput struct {
+	Model    string
+	Versions []string
+	Models   []softwareVersionsModel
+}
+
+func dataSourceSoftwareVersions() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(dataSourceSoftwareVersionsOutput{}, Cfg{
+		"model": {
+			Schema: schema.Schema{
+				Optional:    true,
+				Description: "Only return the versions of gateways of this hardware model, e.g. `iXVirtual`.",
+			},
+		},
+		"versions": {
+			Schema: schema.Schema{
+				Computed: true,
+				Description: "Software versions assigned to gateways of the tenant, oldest first. The orchestrator " +
+					"offers no catalogue of the software, so a version becomes known once it is assigned to a gateway.",
+			},
+		},
+		"models": {
+			Schema: schema.Schema{
+				Computed:    true,
+				Description: "The versions by hardware model, in model order.",
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rt := _dataSourceSoftwareVersions{Binder: binder, InputBinder: inputBinder}
+
+	return &schema.Resource{
+		ReadContext: rt.dataSourceSoftwareVersionsRead,
+		Schema:      swaggerSchema,
+	}, nil
+}
@@ -2,11 +2,10 @@ package bwan
 
 import (
 	"context"
-	"fmt"
+	"net/http"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 
-	"github.com/antihax/optional"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -18,38 +17,33 @@ func (rt _dataSourceTenant) dataSourceTenantsRead(
 	var err error
 	var tenant swagger.Tenant
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	tenantInput, err := ApplyBinderInputResourceData[swagger.Tenant](rt.InputBinder, d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	tenantQueryOpts := swagger.TenantsApiGetAllTenantsOpts{
-		MaxItems: optional.NewInt32(10000),
-	}
-
 	if len(tenantInput.Id) > 0 {
-		tenant, _, err = apiSvc.TenantsApi.GetTenantById(ctx, tenantInput.Id, nil)
+		var resp *http.Response
+		tenant, resp, err = apiSvc.TenantsApi.GetTenantById(ctx, tenantInput.Id, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
+			return apiError("GetTenantById", resp, err, rt.Binder)
 		}
 	} else if len(tenantInput.Name) > 0 {
-		tenantList, _, err := apiSvc.TenantsApi.GetAllTenants(ctx, &tenantQueryOpts)
-		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
-		}
-		for _, t := range tenantList.Data {
+		// The API cannot filter tenants by name, stop paging at the first match.
+		resp, err := forEachTenant(ctx, apiSvc, func(t swagger.Tenant) bool {
 			if t.Name == tenantInput.Name {
 				tenant = t
-				break
+				return false
 			}
+			return true
+		})
+		if err != nil {
+			return apiError("GetAllTenants", resp, err, rt.Binder)
+		}
+		if len(tenant.Name) == 0 {
+			return diag.Errorf("tenant %q not found", tenantInput.Name)
 		}
 	}
 	err = ApplyBinderResourceData(rt.Binder, d, tenant)
@@ -67,19 +61,24 @@ type _dataSourceTenant struct {
 	InputBinder []FieldBinder
 }
 
-func dataSourceTenant() *schema.Resource {
-	swaggerSchema, binder, swaggerInputBinder := ReflectSchema(swagger.Tenant{}, Cfg{
+func dataSourceTenant() (*schema.Resource, error) {
+	swaggerSchema, binder, swaggerInputBinder, err := ReflectSchema(swagger.Tenant{}, Cfg{
+		// Looked up by ID or name.
+		"id": {Schema: schema.Schema{Optional: true, Computed: true}},
 		"name": {
 			Schema: schema.Schema{
 				Optional: true,
 			},
 		},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _dataSourceTenant{Binder: binder, InputBinder: swaggerInputBinder}
 
 	return &schema.Resource{
 		ReadContext: rt.dataSourceTenantsRead,
 		Schema:      swaggerSchema,
-	}
+	}, nil
 }
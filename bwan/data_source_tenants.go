@@ -0,0 +1,100 @@
+package bwan
+
+import (
+	"context"
+	"regexp"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type tenantFilter struct {
+	Name     *regexp.Regexp
+	ParentId string
+}
+
+func (f tenantFilter) match(t swagger.Tenant) bool {
+	if f.Name != nil && !f.Name.MatchString(t.Name) {
+		return false
+	}
+	if f.ParentId != "" && t.ParentId != f.ParentId {
+		return false
+	}
+
+	return true
+}
+
+func (rt _dataSourceTenants) dataSourceTenantsRead(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+
+	var diags diag.Diagnostics
+	var err error
+
+	filter := tenantFilter{
+		ParentId: d.Get("parent_id").(string),
+	}
+	if filter.Name, err = nameRegex(d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	apiSvc := m.(*apiClient)
+
+	var output dataSourceTenantsOutput
+	ids := []string{}
+	resp, err := forEachTenant(ctx, apiSvc, func(t swagger.Tenant) bool {
+		if filter.match(t) {
+			output.Tenants = append(output.Tenants, t)
+			ids = append(ids, t.Id)
+		}
+		return true
+	})
+	if err != nil {
+		return apiError("GetAllTenants", resp, err, rt.Binder)
+	}
+
+	err = ApplyBinderResourceData(rt.Binder, d, output)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(utils.Hash(ids))
+	return diags
+}
+
+type _dataSourceTenants struct {
+	Binder []FieldBinder
+}
+
+type dataSourceTenantsOutput struct {
+	Tenants []swagger.Tenant
+}
+
+func dataSourceTenants() (*schema.Resource, error) {
+	swaggerSchema, binder, _, err := ReflectSchema(dataSourceTenantsOutput{}, Cfg{
+		"tenants": {Schema: schema.Schema{Computed: true}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	swaggerSchema["ids"] = idsSchema()
+	swaggerSchema["name_regex"] = nameRegexSchema()
+	swaggerSchema["parent_id"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Only return direct children of the tenant with this ID.",
+	}
+
+	rt := _dataSourceTenants{Binder: binder}
+
+	return &schema.Resource{
+		ReadContext: rt.dataSourceTenantsRead,
+		Schema:      swaggerSchema,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package bwan
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestTenantFilter(t *testing.T) {
+	tenants := []swagger.Tenant{
+		{Id: "1", Name: "msp"},
+		{Id: "2", Name: "acme", ParentId: "1"},
+		{Id: "3", Name: "acme-lab", ParentId: "2"},
+		{Id: "4", Name: "globex", ParentId: "1"},
+	}
+
+	tests := []struct {
+		name   string
+		filter tenantFilter
+		ids    []string
+	}{
+		{"none", tenantFilter{}, []string{"1", "2", "3", "4"}},
+		{"parent", tenantFilter{ParentId: "1"}, []string{"2", "4"}},
+		{"grandparent", tenantFilter{ParentId: "2"}, []string{"3"}},
+		{"name", tenantFilter{Name: regexp.MustCompile("^acme")}, []string{"2", "3"}},
+		{"combined", tenantFilter{Name: regexp.MustCompile("^acme"), ParentId: "1"}, []string{"2"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ids []string
+			for _, tenant := range tenants {
+				if test.filter.match(tenant) {
+					ids = append(ids, tenant.Id)
+				}
+			}
+			assert.Equal(t, test.ids, ids)
+		})
+	}
+}
+
+func TestDataSourceTenantsRead(t *testing.T) {
+	client, queries := pagingServer(t, 2*listPageSize+5)
+
+	ds := mustResource(t, dataSourceTenants)
+	d := schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"parent_id":  "parent-1",
+		"name_regex": "-40[0-9]$",
+	})
+
+	diags := ds.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	// Tenants of the last page are found, after reading all of them.
+	assert.Equal(t, []interface{}{"400", "403"}, d.Get("ids"))
+	assert.Equal(t, "tenant-403", d.Get("tenants.1.name"))
+	assert.Len(t, *queries, 3)
+	assert.NotEmpty(t, d.Id())
+}
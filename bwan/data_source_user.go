@@ -2,7 +2,7 @@ package bwan
 
 import (
 	"context"
-	"fmt"
+	"net/http"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 
@@ -22,23 +22,18 @@ func (rt _dataSourceUser) dataSourceUserRead(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(userInput.Id) > 0 {
-		user, _, err = apiSvc.UsersApi.GetUserById(ctx, userInput.Id, nil)
+		var resp *http.Response
+		user, resp, err = apiSvc.UsersApi.GetUserById(ctx, userInput.Id, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
+			return apiError("GetUserById", resp, err, rt.Binder)
 		}
 	} else if len(userInput.Name) > 0 {
-		userList, _, err := apiSvc.UsersApi.GetAllUsers(ctx, nil)
+		userList, resp, err := apiSvc.UsersApi.GetAllUsers(ctx, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
+			return apiError("GetAllUsers", resp, err, rt.Binder)
 		}
 		for _, u := range userList {
 			if user.Name == userInput.Name {
@@ -67,19 +62,24 @@ type _dataSourceUser struct {
 	InputBinder []FieldBinder
 }
 
-func dataSourceUser() *schema.Resource {
-	swaggerSchema, binder, swaggerInputBinder := ReflectSchema(swagger.User{}, Cfg{
+func dataSourceUser() (*schema.Resource, error) {
+	swaggerSchema, binder, swaggerInputBinder, err := ReflectSchema(swagger.User{}, Cfg{
+		// Looked up by ID or name.
+		"id": {Schema: schema.Schema{Optional: true, Computed: true}},
 		"name": {
 			Schema: schema.Schema{
 				Optional: true,
 			},
 		},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _dataSourceUser{Binder: binder, InputBinder: swaggerInputBinder}
 
 	return &schema.Resource{
 		ReadContext: rt.dataSourceUserRead,
 		Schema:      swaggerSchema,
-	}
+	}, nil
 }
@@ -0,0 +1,121 @@
+package bwan
+
+import (
+	"context"
+	"regexp"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+type userFilter struct {
+	Name  *regexp.Regexp
+	Roles []string
+}
+
+func (f userFilter) match(u swagger.User) bool {
+	if f.Name != nil && !f.Name.MatchString(u.Name) {
+		return false
+	}
+	if len(f.Roles) == 0 {
+		return true
+	}
+
+	for _, role := range u.Roles {
+		for _, r := range f.Roles {
+			if string(role) == r {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (rt _dataSourceUsers) dataSourceUsersRead(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+
+	var diags diag.Diagnostics
+	var err error
+
+	filter := userFilter{
+		Roles: stringList(d, "roles"),
+	}
+	if filter.Name, err = nameRegex(d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	apiSvc := m.(*apiClient)
+
+	userList, resp, err := apiSvc.UsersApi.GetAllUsers(ctx, nil)
+	if err != nil {
+		return apiError("GetAllUsers", resp, err, rt.Binder)
+	}
+
+	var output dataSourceUsersOutput
+	ids := []string{}
+	for _, u := range userList {
+		if filter.match(u) {
+			output.Users = append(output.Users, u)
+			ids = append(ids, u.Id)
+		}
+	}
+
+	err = ApplyBinderResourceData(rt.Binder, d, output)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(utils.Hash(ids))
+	return diags
+}
+
+type _dataSourceUsers struct {
+	Binder []FieldBinder
+}
+
+type dataSourceUsersOutput struct {
+	Users []swagger.User
+}
+
+func dataSourceUsers() (*schema.Resource, error) {
+	swaggerSchema, binder, _, err := ReflectSchema(dataSourceUsersOutput{}, Cfg{
+		"users": {Schema: schema.Schema{Computed: true}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	swaggerSchema["ids"] = idsSchema()
+	swaggerSchema["name_regex"] = nameRegexSchema()
+	swaggerSchema["roles"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Only return users holding at least one of these roles, e.g. `Admin`.",
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+			ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{
+				string(swagger.SYSTEM_ADMIN_UserRole),
+				string(swagger.SYSTEM_OPERATOR_UserRole),
+				string(swagger.SYSTEM_MONITOR_UserRole),
+				string(swagger.ADMIN_UserRole),
+				string(swagger.OPERATOR_UserRole),
+				string(swagger.MONITOR_UserRole),
+			}, false)),
+		},
+	}
+
+	rt := _dataSourceUsers{Binder: binder}
+
+	return &schema.Resource{
+		ReadContext: rt.dataSourceUsersRead,
+		Schema:      swaggerSchema,
+	}, nil
+}
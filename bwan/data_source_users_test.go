@@ -0,0 +1,79 @@
+package bwan
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testUsers() []swagger.User {
+	return []swagger.User{
+		{Id: "1", Name: "alice", Email: "alice@example.com",
+			Roles: []swagger.UserRole{swagger.ADMIN_UserRole}},
+		{Id: "2", Name: "bob", Email: "bob@example.com",
+			Roles: []swagger.UserRole{swagger.OPERATOR_UserRole, swagger.MONITOR_UserRole}},
+		{Id: "3", Name: "carol", Email: "carol@example.com"},
+	}
+}
+
+func TestUserFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter userFilter
+		ids    []string
+	}{
+		{"none", userFilter{}, []string{"1", "2", "3"}},
+		{"role", userFilter{Roles: []string{"Admin"}}, []string{"1"}},
+		{"second role", userFilter{Roles: []string{"Monitor"}}, []string{"2"}},
+		{"any role", userFilter{Roles: []string{"Admin", "Operator"}}, []string{"1", "2"}},
+		{"unheld role", userFilter{Roles: []string{"System Admin"}}, nil},
+		{"name", userFilter{Name: regexp.MustCompile("^(alice|carol)$")}, []string{"1", "3"}},
+		{"combined", userFilter{Name: regexp.MustCompile("^b"), Roles: []string{"Admin"}}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ids []string
+			for _, u := range testUsers() {
+				if test.filter.match(u) {
+					ids = append(ids, u.Id)
+				}
+			}
+			assert.Equal(t, test.ids, ids)
+		})
+	}
+}
+
+func TestDataSourceUsersRead(t *testing.T) {
+	client := testAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(testUsers())
+	}))
+
+	ds := mustResource(t, dataSourceUsers)
+	d := schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"roles": []interface{}{"Operator", "Admin"},
+	})
+
+	diags := ds.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, []interface{}{"1", "2"}, d.Get("ids"))
+	assert.Equal(t, "bob@example.com", d.Get("users.1.email"))
+	assert.NotEmpty(t, d.Id())
+
+	d = schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"roles": []interface{}{"System Monitor"},
+	})
+	diags = ds.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, []interface{}{}, d.Get("ids"))
+	assert.Empty(t, d.Get("users"))
+}
@@ -0,0 +1,81 @@
+package bwan
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// removedOutOfBand returns the warning reported when a gateway sub-resource
+// is no longer configured on its edge. The edge's last modification is
+// included so the out-of-band change can be traced back.
+func removedOutOfBand(gateway swagger.Edge, what string) diag.Diagnostics {
+	modifiedBy, modifiedAt := lastModified(gateway)
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%s was removed outside of Terraform", what),
+			Detail: fmt.Sprintf(
+				"%s is no longer configured on gateway %q (%s); Terraform will plan to re-create it. "+
+					"The gateway was last modified by %s at %s.",
+				what, gateway.Name, gateway.Id, modifiedBy, modifiedAt),
+		},
+	}
+}
+
+// changedConcurrently returns the error reported when an edge write gave up
+// because the edge, or the item the write was about, changed concurrently.
+func changedConcurrently(e edgeConflictError) diag.Diagnostics {
+	modifiedBy, modifiedAt := lastModified(e.gateway)
+
+	if e.what == "" {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Gateway %q keeps changing", e.gateway.Name),
+				Detail: fmt.Sprintf(
+					"Gateway %q (%s) was modified outside of Terraform on each of %d attempts "+
+						"to update it, last by %s at %s. No changes were applied.",
+					e.gateway.Name, e.gateway.Id, e.attempts, modifiedBy, modifiedAt),
+			},
+		}
+	}
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Conflicting change to %s", e.what),
+			Detail: fmt.Sprintf(
+				"%s of gateway %q (%s) was modified by %s at %s while Terraform was updating it. "+
+					"The change was not applied; refresh and review the remote change before applying again.",
+				strings.ToUpper(e.what[:1])+e.what[1:], e.gateway.Name, e.gateway.Id, modifiedBy, modifiedAt),
+		},
+	}
+}
+
+// lastModified describes who modified the edge last and when.
+func lastModified(gateway swagger.Edge) (by, at string) {
+	by = "an unknown user"
+	if gateway.ModifiedBy != nil {
+		switch {
+		case gateway.ModifiedBy.Email != "":
+			by = gateway.ModifiedBy.Email
+		case gateway.ModifiedBy.Name != "":
+			by = gateway.ModifiedBy.Name
+		case gateway.ModifiedBy.Id != "":
+			by = gateway.ModifiedBy.Id
+		}
+	}
+
+	at = "an unknown time"
+	if !gateway.DateModified.IsZero() {
+		at = gateway.DateModified.Format(time.RFC3339)
+	}
+
+	return by, at
+}
@@ -0,0 +1,75 @@
+package bwan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemovedOutOfBand(t *testing.T) {
+	diags := removedOutOfBand(swagger.Edge{
+		Id:           "gw1",
+		Name:         "hub",
+		ModifiedBy:   &swagger.UserRef{Email: "admin@example.com"},
+		DateModified: time.Date(2024, 5, 8, 5, 30, 30, 0, time.UTC),
+	}, `Static route "10.1.0.0/16"`)
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, `Static route "10.1.0.0/16" was removed outside of Terraform`, diags[0].Summary)
+	assert.Equal(t, `Static route "10.1.0.0/16" is no longer configured on gateway "hub" (gw1); `+
+		`Terraform will plan to re-create it. `+
+		`The gateway was last modified by admin@example.com at 2024-05-08T05:30:30Z.`, diags[0].Detail)
+
+	diags = removedOutOfBand(swagger.Edge{Id: "gw1", Name: "hub"}, `BGP peer "10.0.0.2"`)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Detail, "last modified by an unknown user at an unknown time.")
+}
+
+func TestReadRemovedOutOfBand(t *testing.T) {
+	_, client := newFakeEdgeAPI(t, swagger.Edge{
+		Id:           "gw1",
+		Name:         "hub",
+		ModifiedBy:   &swagger.UserRef{Name: "admin"},
+		DateModified: time.Date(2024, 5, 8, 5, 30, 30, 0, time.UTC),
+	})
+
+	withGateway := func(raw map[string]interface{}) map[string]interface{} {
+		raw["gateway_id"] = "gw1"
+		return raw
+	}
+	tests := []struct {
+		resource func() (*schema.Resource, error)
+		raw      map[string]interface{}
+		summary  string
+	}{
+		{resourceGatewayStaticRoute, withGateway(testRoute("10.1.0.0/16")),
+			`Static route "10.1.0.0/16" was removed outside of Terraform`},
+		{resourceGatewayBgp, withGateway(testPeer("10.0.0.2", 65002)),
+			`BGP peer "10.0.0.2" was removed outside of Terraform`},
+		{resourceGatewayNat, withGateway(map[string]interface{}{
+			"name": "web", "public_ip": "1.1.1.2", "up_link_if_name": "GE1", "lan_ip": "192.168.1.2",
+		}), `NAT rule "web" was removed outside of Terraform`},
+		{resourceGatewayPortForward, testPortForwardConfig("gw1"),
+			`Port forwarding rule "ssh" was removed outside of Terraform`},
+	}
+	for _, test := range tests {
+		r := mustResource(t, test.resource)
+		d := schema.TestResourceDataRaw(t, r.Schema, test.raw)
+		d.SetId("gw1:item")
+
+		diags := r.ReadContext(context.Background(), d, client)
+		require.Len(t, diags, 1, test.summary)
+		assert.Equal(t, diag.Warning, diags[0].Severity)
+		assert.Equal(t, test.summary, diags[0].Summary)
+		assert.Contains(t, diags[0].Detail, `gateway "hub" (gw1); Terraform will plan to re-create it.`)
+		assert.Contains(t, diags[0].Detail, "last modified by admin at 2024-05-08T05:30:30Z.")
+		assert.Empty(t, d.Id(), test.summary)
+	}
+}
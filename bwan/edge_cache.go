@@ -0,0 +1,144 @@
+package bwan
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type freshEdgeKey struct{}
+
+// withFreshEdge returns a context whose edge reads bypass the edge cache,
+// for callers that need the current state of the orchestrator, such as a
+// read-modify-write or a poll.
+func withFreshEdge(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshEdgeKey{}, true)
+}
+
+// edgeCache is an http.RoundTripper caching reads of single edges
+// (GET .../edges/{id}) for the lifetime of the provider. During a plan or
+// apply every sub-resource of a gateway reads the same edge; with the cache
+// the edge is fetched once, and concurrent reads of an uncached edge share
+// one request. Any other request addressing the edge, e.g. a PUT of the edge
+// or of one of its interfaces, drops the cached edge.
+type edgeCache struct {
+	next http.RoundTripper
+
+	mu      sync.Mutex
+	entries map[string]*edgeCacheEntry
+}
+
+type edgeCacheEntry struct {
+	done chan struct{}
+
+	status     string
+	statusCode int
+	header     http.Header
+	body       []byte
+	err        error
+}
+
+func newEdgeCache(next http.RoundTripper) *edgeCache {
+	return &edgeCache{next: next, entries: map[string]*edgeCacheEntry{}}
+}
+
+// edgePath splits a request path of the form ".../edges/{id}[/...]" into the
+// edge ID and whether the path addresses the edge itself.
+func edgePath(path string) (id string, edge bool) {
+	i := strings.LastIndex(path, "/edges/")
+	if i < 0 {
+		return "", false
+	}
+
+	id, rest, _ := strings.Cut(path[i+len("/edges/"):], "/")
+	return id, rest == ""
+}
+
+func (c *edgeCache) RoundTrip(req *http.Request) (*http.Response, error) {
+	id, edge := edgePath(req.URL.Path)
+	if id == "" {
+		return c.next.RoundTrip(req)
+	}
+
+	if req.Method != http.MethodGet {
+		// Drop the edge both before and after the write, so that neither a
+		// read racing the write nor one following it sees the old edge.
+		c.invalidate(id)
+		defer c.invalidate(id)
+
+		return c.next.RoundTrip(req)
+	}
+
+	if !edge {
+		return c.next.RoundTrip(req)
+	}
+
+	fresh, _ := req.Context().Value(freshEdgeKey{}).(bool)
+
+	c.mu.Lock()
+	e, ok := c.entries[id]
+	if ok && !fresh {
+		c.mu.Unlock()
+		<-e.done
+		return e.response(req)
+	}
+	e = &edgeCacheEntry{done: make(chan struct{})}
+	c.entries[id] = e
+	c.mu.Unlock()
+
+	e.fetch(c.next, req)
+	close(e.done)
+
+	if e.err != nil || e.statusCode != http.StatusOK {
+		c.mu.Lock()
+		if c.entries[id] == e {
+			delete(c.entries, id)
+		}
+		c.mu.Unlock()
+	}
+
+	return e.response(req)
+}
+
+func (c *edgeCache) invalidate(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, id)
+}
+
+func (e *edgeCacheEntry) fetch(next http.RoundTripper, req *http.Request) {
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		e.err = err
+		return
+	}
+	defer resp.Body.Close()
+
+	e.status = resp.Status
+	e.statusCode = resp.StatusCode
+	e.header = resp.Header
+	e.body, e.err = io.ReadAll(resp.Body)
+}
+
+// response returns a copy of the cached response for req.
+func (e *edgeCacheEntry) response(req *http.Request) (*http.Response, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return &http.Response{
+		Status:        e.status,
+		StatusCode:    e.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}, nil
+}
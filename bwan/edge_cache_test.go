@@ -0,0 +1,150 @@
+package bwan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// cachedAPIClient returns a client reading through an edge cache from api,
+// as configured by the provider.
+func cachedAPIClient(t *testing.T, api http.Handler) *apiClient {
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	cfg := swagger.NewConfiguration()
+	cfg.BasePath = srv.URL + "/v1"
+	cfg.HTTPClient = &http.Client{Transport: newEdgeCache(http.DefaultTransport)}
+	return newAPIClient(cfg)
+}
+
+// routeStates returns the resource data of the static routes of gw1 as
+// found in the state after an apply.
+func routeStates(t *testing.T, r *schema.Resource, n int) []*schema.ResourceData {
+	var ds []*schema.ResourceData
+	for i := 1; i <= n; i++ {
+		route := testRoute(fmt.Sprintf("10.%d.0.0/16", i))
+		route["gateway_id"] = "gw1"
+		d := schema.TestResourceDataRaw(t, r.Schema, route)
+		d.SetId(compositeId("gw1", route["destination"].(string)))
+		ds = append(ds, d)
+	}
+	return ds
+}
+
+func TestEdgeCacheRefresh(t *testing.T) {
+	var routes []swagger.StaticRoute
+	for i := 1; i <= 30; i++ {
+		routes = append(routes, swagger.StaticRoute{
+			Destination: fmt.Sprintf("10.%d.0.0/16", i), Device: "GE1", Nhop: "10.0.0.1", Cost: 1,
+		})
+	}
+	edges := func() []swagger.Edge {
+		return []swagger.Edge{
+			{Id: "gw1", StaticRoutes: routes},
+			{Id: "gw2", StaticRoutes: routes[:1]},
+		}
+	}
+
+	r := mustResource(t, resourceGatewayStaticRoute)
+	refresh := func(client *apiClient) {
+		all := applyConcurrently(routeStates(t, r, 30), func(d *schema.ResourceData) diag.Diagnostics {
+			return r.ReadContext(context.Background(), d, client)
+		})
+		for _, diags := range all {
+			require.False(t, diags.HasError(), "%v", diags)
+		}
+	}
+
+	uncached, client := newFakeEdgeAPI(t, edges()...)
+	refresh(client)
+	assert.Equal(t, 30, uncached.count("GET"))
+
+	api, _ := newFakeEdgeAPI(t, edges()...)
+	client = cachedAPIClient(t, http.StripPrefix("/v1", api))
+	refresh(client)
+	assert.Equal(t, 1, api.count("GET"))
+
+	// A second refresh is served from the cache, other edges are not.
+	refresh(client)
+	_, _, err := client.EdgesApi.GetEdgeById(context.Background(), "gw2", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, api.count("GET"))
+
+	// Reads asking for the current edge go to the API, and refresh the cache.
+	_, _, err = client.EdgesApi.GetEdgeById(withFreshEdge(context.Background()), "gw1", nil)
+	require.NoError(t, err)
+	refresh(client)
+	assert.Equal(t, 3, api.count("GET"))
+}
+
+func TestEdgeCacheInvalidation(t *testing.T) {
+	api, _ := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"}, swagger.Edge{Id: "gw2"})
+	client := cachedAPIClient(t, http.StripPrefix("/v1", api))
+	client.writer = newEdgeWriter(client, time.Millisecond)
+	ctx := context.Background()
+
+	gateway, _, err := client.EdgesApi.GetEdgeById(ctx, "gw1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, gateway.StaticRoutes)
+	_, _, err = client.EdgesApi.GetEdgeById(ctx, "gw2", nil)
+	require.NoError(t, err)
+
+	// The write reads the current edge despite the cache, and drops the
+	// cached copy of that edge only.
+	r := mustResource(t, resourceGatewayStaticRoute)
+	d := routeStates(t, r, 1)[0]
+	diags := r.CreateContext(ctx, d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 3, api.count("GET"))
+
+	gateway, _, err = client.EdgesApi.GetEdgeById(ctx, "gw1", nil)
+	require.NoError(t, err)
+	assert.Len(t, gateway.StaticRoutes, 1)
+	_, _, err = client.EdgesApi.GetEdgeById(ctx, "gw2", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, api.count("GET"))
+
+	// So does a write through the generated client.
+	_, _, err = client.EdgesApi.UpdateEdgeById(ctx, swagger.UpdateEdgeInput{Name: "renamed"}, "gw1", nil)
+	require.NoError(t, err)
+	gateway, _, err = client.EdgesApi.GetEdgeById(ctx, "gw1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", gateway.Name)
+	assert.Equal(t, 5, api.count("GET"))
+}
+
+func TestEdgeCacheErrorsAreNotCached(t *testing.T) {
+	api, _ := newFakeEdgeAPI(t)
+	client := cachedAPIClient(t, http.StripPrefix("/v1", api))
+
+	for i := 0; i < 2; i++ {
+		_, resp, err := client.EdgesApi.GetEdgeById(context.Background(), "gw1", nil)
+		require.Error(t, err)
+		assert.True(t, client.isNotFound(err, resp, "/edges/gw1"))
+	}
+	assert.Equal(t, 2, api.count("GET"))
+}
+
+func TestEdgePath(t *testing.T) {
+	for path, want := range map[string][2]interface{}{
+		"/v1/edges/gw1":                {"gw1", true},
+		"/v1/edges/gw1/interfaces/GE1": {"gw1", false},
+		"/v1/edges":                    {"", false},
+		"/v1/edges/":                   {"", true},
+		"/v1/tenants/t1":               {"", false},
+	} {
+		id, edge := edgePath(path)
+		assert.Equal(t, want, [2]interface{}{id, edge}, path)
+	}
+}
@@ -0,0 +1,342 @@
+package bwan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
+)
+
+// edgeWriteWindow is how long the first write to an edge waits for further
+// writes to the same edge before the batch is sent. Terraform applies
+// independent resources in parallel, so the sub-resources of one gateway
+// usually arrive well within it.
+const edgeWriteWindow = 50 * time.Millisecond
+
+// edgeWriteAttempts bounds how often a batch is re-read and re-applied when
+// the edge changes between the read and the update.
+const edgeWriteAttempts = 4
+
+// edgeMutation changes the lists of an edge, e.g. adds a static route.
+// Lists names the JSON keys of the lists Apply may change, which are sent in
+// full, including when they end up empty. Apply must leave the edge
+// untouched when it fails.
+//
+// Item returns the item of the edge the mutation changes, or nil if the
+// edge does not have it. When the edge changes concurrently, the mutation is
+// re-applied to the new edge unless its item changed too, which is reported
+// as a conflict on What, e.g. `static route "10.0.0.0/8"`.
+type edgeMutation struct {
+	Lists []string
+	Apply func(gateway *swagger.Edge) error
+	Item  func(gateway *swagger.Edge) interface{}
+	What  string
+}
+
+// edgeAPIError is a failed request of a batched edge write, carrying the
+// operation and response for apiError.
+type edgeAPIError struct {
+	op   string
+	resp *http.Response
+	err  error
+}
+
+func (e edgeAPIError) Error() string {
+	return e.op + ": " + e.err.Error()
+}
+
+func (e edgeAPIError) Unwrap() error {
+	return e.err
+}
+
+// edgeConflictError reports that the item described by what, or the edge
+// as a whole if what is empty, was changed by someone else while the
+// provider was updating it. gateway is the edge as last read.
+type edgeConflictError struct {
+	gateway  swagger.Edge
+	what     string
+	attempts int
+}
+
+func (e edgeConflictError) Error() string {
+	if e.what == "" {
+		return fmt.Sprintf("gateway %s was changed concurrently %d times", e.gateway.Id, e.attempts)
+	}
+	return fmt.Sprintf("%s of gateway %s was changed concurrently", e.what, e.gateway.Id)
+}
+
+// edgeWriter coordinates the read-modify-write cycles of the gateway
+// sub-resources. Mutations of one edge arriving within the window are
+// applied to a single read of the edge and sent as a single update, instead
+// of one serialized GET and PUT per sub-resource.
+//
+// The lock only serializes writers of this provider. Other clients are
+// detected through the edge's ETag, sent as If-Match, or where the API does
+// not send one, through the edge's modification date, sent as
+// If-Unmodified-Since. The batch is then re-read and re-applied, up to
+// edgeWriteAttempts times.
+type edgeWriter struct {
+	api    *apiClient
+	window time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*edgeBatch
+}
+
+type edgeBatch struct {
+	writes []*edgeWrite
+	done   chan struct{}
+}
+
+type edgeWrite struct {
+	mutation edgeMutation
+	// deadline is the deadline of the caller, zero if it has none.
+	deadline time.Time
+	conflict error
+
+	gateway swagger.Edge
+	err     error
+}
+
+// context returns the context of the requests of the batch. The batch
+// outlives the request that opened it, so the context is not tied to any
+// one caller: it lasts until the latest deadline of the callers, or has no
+// deadline if any of them has none.
+func (b *edgeBatch) context() (context.Context, context.CancelFunc) {
+	var latest time.Time
+	for _, write := range b.writes {
+		if write.deadline.IsZero() {
+			return context.WithCancel(context.Background())
+		}
+		if write.deadline.After(latest) {
+			latest = write.deadline
+		}
+	}
+
+	return context.WithDeadline(context.Background(), latest)
+}
+
+func newEdgeWriter(api *apiClient, window time.Duration) *edgeWriter {
+	return &edgeWriter{
+		api:     api,
+		window:  window,
+		pending: map[string]*edgeBatch{},
+	}
+}
+
+// update applies the mutation to the edge and returns the edge as updated
+// by the batch the mutation was part of. A mutation is still applied if ctx
+// is cancelled after it was queued. If the API rejects the update of the
+// batch, its mutations are sent one by one, so that the error is returned
+// for the mutations causing it only.
+func (w *edgeWriter) update(ctx context.Context, id string, mutation edgeMutation) (swagger.Edge, error) {
+	write := &edgeWrite{mutation: mutation}
+	write.deadline, _ = ctx.Deadline()
+
+	w.mu.Lock()
+	b, ok := w.pending[id]
+	if !ok {
+		b = &edgeBatch{done: make(chan struct{})}
+		w.pending[id] = b
+		time.AfterFunc(w.window, func() { w.flush(id, b) })
+	}
+	b.writes = append(b.writes, write)
+	w.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return swagger.Edge{}, ctx.Err()
+	}
+
+	return write.gateway, write.err
+}
+
+func (w *edgeWriter) flush(id string, b *edgeBatch) {
+	defer close(b.done)
+
+	w.mu.Lock()
+	delete(w.pending, id)
+	w.mu.Unlock()
+
+	ctx, cancel := b.context()
+	defer cancel()
+
+	// Writes that do not go through the writer still take the edge lock.
+	lock := utils.Mutex.Get(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	sent, err := w.write(ctx, id, b.writes)
+
+	// The API rejects the update as a whole, whichever of the mutations is
+	// at fault. Sending them one by one fails only the resources causing
+	// the rejection.
+	var aerr edgeAPIError
+	if len(sent) > 1 && errors.As(err, &aerr) && aerr.op == "UpdateEdgeById" &&
+		aerr.resp != nil && aerr.resp.StatusCode >= 400 && aerr.resp.StatusCode < 500 {
+		for _, write := range sent {
+			w.write(ctx, id, []*edgeWrite{write})
+		}
+	}
+}
+
+// write applies the writes to the edge and sends them as a single update,
+// re-reading the edge and re-applying them when it changes concurrently. It
+// returns the writes that were sent and the error of the update, which is
+// set on the writes it failed.
+func (w *edgeWriter) write(ctx context.Context, id string, writes []*edgeWrite) ([]*edgeWrite, error) {
+	fail := func(writes []*edgeWrite, err error) ([]*edgeWrite, error) {
+		for _, write := range writes {
+			write.err = err
+		}
+		return writes, err
+	}
+
+	var prior *swagger.Edge
+	for attempt := 1; ; attempt++ {
+		gateway, version, err := w.read(ctx, id)
+		if err != nil {
+			return fail(writes, err)
+		}
+
+		// Re-applying a mutation to a changed edge is only safe if its own
+		// item was left alone.
+		if prior != nil {
+			for _, write := range writes {
+				if write.conflict == nil && write.mutation.Item != nil &&
+					!reflect.DeepEqual(write.mutation.Item(prior), write.mutation.Item(&gateway)) {
+					write.conflict = edgeConflictError{gateway: gateway, what: write.mutation.What}
+				}
+			}
+		}
+
+		read, err := cloneEdge(gateway)
+		if err != nil {
+			return fail(writes, err)
+		}
+		prior = &read
+
+		var lists []string
+		var sent []*edgeWrite
+		for _, write := range writes {
+			if write.conflict != nil {
+				write.err = write.conflict
+				continue
+			}
+			write.err = write.mutation.Apply(&gateway)
+			if write.err == nil {
+				lists = append(lists, write.mutation.Lists...)
+				sent = append(sent, write)
+			}
+		}
+
+		if len(sent) == 0 {
+			for _, write := range writes {
+				write.gateway = gateway
+			}
+			return nil, nil
+		}
+
+		body, err := edgeListsBody(gateway, lists)
+		if err != nil {
+			return fail(sent, err)
+		}
+
+		updated, resp, err := w.api.putEdge(ctx, id, body, version)
+		if resp != nil && resp.StatusCode == http.StatusPreconditionFailed {
+			if attempt == edgeWriteAttempts {
+				return fail(sent, edgeConflictError{gateway: read, attempts: attempt})
+			}
+			continue
+		}
+		if err != nil {
+			return fail(sent, edgeAPIError{op: "UpdateEdgeById", resp: resp, err: err})
+		}
+
+		for _, write := range writes {
+			write.gateway = updated
+		}
+		return sent, nil
+	}
+}
+
+// read returns the current edge and its version.
+func (w *edgeWriter) read(ctx context.Context, id string) (swagger.Edge, edgeVersion, error) {
+	// The mutations must be applied to the current edge, not a cached one.
+	gateway, resp, err := w.api.EdgesApi.GetEdgeById(withFreshEdge(ctx), id, nil)
+	if err != nil {
+		return gateway, edgeVersion{}, edgeAPIError{op: "GetEdgeById", resp: resp, err: err}
+	}
+
+	return gateway, edgeVersion{etag: resp.Header.Get("ETag"), modified: gateway.DateModified}, nil
+}
+
+// edgeGone reports whether an edge write failed because the edge does not
+// exist.
+func (c *apiClient) edgeGone(err error, id string) bool {
+	var aerr edgeAPIError
+	return errors.As(err, &aerr) && c.isNotFound(aerr.err, aerr.resp, "/edges/"+id)
+}
+
+// cloneEdge returns a deep copy of the edge, so that mutations applied to
+// the edge leave the copy untouched.
+func cloneEdge(gateway swagger.Edge) (swagger.Edge, error) {
+	var clone swagger.Edge
+
+	raw, err := json.Marshal(gateway)
+	if err != nil {
+		return clone, err
+	}
+
+	return clone, json.Unmarshal(raw, &clone)
+}
+
+// edgeListsBody returns an update body carrying the given lists of the
+// edge. Empty lists, which the omitempty tags of swagger.Edge drop, are sent
+// as [] so that removing the last item takes effect.
+func edgeListsBody(gateway swagger.Edge, lists []string) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(gateway)
+	if err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+
+	body := map[string]json.RawMessage{}
+	for _, list := range lists {
+		if v, ok := all[list]; ok {
+			body[list] = v
+		} else {
+			body[list] = json.RawMessage("[]")
+		}
+	}
+
+	return body, nil
+}
+
+// updateEdgeFields sets the fields of input that are not empty on the edge,
+// leaving the rest of it alone. Unlike an edgeMutation it does not re-read
+// the edge, as the fields do not depend on it.
+func updateEdgeFields(ctx context.Context, apiSvc *apiClient, id string, input swagger.UpdateEdgeInput) error {
+	lock := utils.Mutex.Get(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	_, resp, err := apiSvc.EdgesApi.UpdateEdgeById(ctx, input, id, nil)
+	if err != nil {
+		return edgeAPIError{op: "UpdateEdgeById", resp: resp, err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,404 @@
+package bwan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// applyConcurrently runs fn for every resource data in its own goroutine,
+// as Terraform does for independent resources, and returns the diagnostics
+// in order.
+func applyConcurrently(ds []*schema.ResourceData,
+	fn func(d *schema.ResourceData) diag.Diagnostics) []diag.Diagnostics {
+	out := make([]diag.Diagnostics, len(ds))
+
+	var wg sync.WaitGroup
+	for i, d := range ds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out[i] = fn(d)
+		}()
+	}
+	wg.Wait()
+
+	return out
+}
+
+func TestEdgeWriterBatchesConcurrentResources(t *testing.T) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{
+		Id: "gw1",
+		StaticRoutes: []swagger.StaticRoute{
+			{Destination: "192.168.0.0/24", Device: "GE2", Nhop: "10.0.1.1", Cost: 5},
+		},
+	})
+	client.writer = newEdgeWriter(client, 200*time.Millisecond)
+
+	routes := mustResource(t, resourceGatewayStaticRoute)
+	peers := mustResource(t, resourceGatewayBgp)
+
+	var ds []*schema.ResourceData
+	for i := 1; i <= 20; i++ {
+		route := testRoute(fmt.Sprintf("10.%d.0.0/16", i))
+		route["gateway_id"] = "gw1"
+		ds = append(ds, schema.TestResourceDataRaw(t, routes.Schema, route))
+	}
+	for i := 1; i <= 5; i++ {
+		peer := testPeer(fmt.Sprintf("10.0.0.%d", i), 65000+i)
+		peer["gateway_id"] = "gw1"
+		ds = append(ds, schema.TestResourceDataRaw(t, peers.Schema, peer))
+	}
+
+	all := applyConcurrently(ds, func(d *schema.ResourceData) diag.Diagnostics {
+		if _, ok := d.GetOk("destination"); ok {
+			return routes.CreateContext(context.Background(), d, client)
+		}
+		return peers.CreateContext(context.Background(), d, client)
+	})
+	for _, diags := range all {
+		require.False(t, diags.HasError(), "%v", diags)
+	}
+
+	assert.Equal(t, 1, api.count("GET"))
+	assert.Equal(t, 1, api.count("PUT"))
+
+	gateway := api.edge("gw1")
+	assert.Len(t, gateway.StaticRoutes, 21)
+	assert.Len(t, gateway.BgpConfiguration, 5)
+
+	// Every resource got its own item of the shared result.
+	for i, d := range ds[:20] {
+		assert.Equal(t, fmt.Sprintf("gw1/10.%d.0.0/16", i+1), d.Id())
+		assert.Equal(t, 1, d.Get("cost"))
+	}
+	for i, d := range ds[20:] {
+		assert.Equal(t, fmt.Sprintf("gw1/10.0.0.%d", i+1), d.Id())
+		assert.Equal(t, 400, d.Get("local_as"))
+	}
+
+	// Deleting all of them empties the lists, which needs explicit empty
+	// lists in the update.
+	all = applyConcurrently(ds, func(d *schema.ResourceData) diag.Diagnostics {
+		if _, ok := d.GetOk("destination"); ok {
+			return routes.DeleteContext(context.Background(), d, client)
+		}
+		return peers.DeleteContext(context.Background(), d, client)
+	})
+	for _, diags := range all {
+		require.False(t, diags.HasError(), "%v", diags)
+	}
+
+	assert.Equal(t, 2, api.count("PUT"))
+	gateway = api.edge("gw1")
+	assert.Equal(t, []swagger.StaticRoute{
+		{Destination: "192.168.0.0/24", Device: "GE2", Nhop: "10.0.1.1", Cost: 5},
+	}, gateway.StaticRoutes)
+	assert.Empty(t, gateway.BgpConfiguration)
+	assert.Contains(t, api.bodies[1], `"bgpConfiguration":[]`)
+}
+
+func TestEdgeWriterListResources(t *testing.T) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1", DateModified: time.Unix(1700000000, 0).UTC()})
+	client.writer = newEdgeWriter(client, 200*time.Millisecond)
+	api.noETag = true
+
+	type list struct {
+		r   *schema.Resource
+		raw map[string]interface{}
+	}
+	lists := []list{
+		{mustResource(t, resourceGatewayStaticRoutes), map[string]interface{}{
+			"route": []interface{}{testRoute("10.1.0.0/16")},
+		}},
+		{mustResource(t, resourceGatewayBgpPeers), map[string]interface{}{
+			"peer": []interface{}{testPeer("10.0.0.1", 65001)},
+		}},
+		{mustResource(t, resourceGatewayNatRules), map[string]interface{}{
+			"rule": []interface{}{testPortForward("web", "1.1.1.2", 0)},
+		}},
+		{mustResource(t, resourceGatewayPortForwardRules), map[string]interface{}{
+			"rule": []interface{}{testPortForward("ssh", "1.1.1.3", 22)},
+		}},
+	}
+	var ds []*schema.ResourceData
+	for _, l := range lists {
+		l.raw["gateway_id"] = "gw1"
+		ds = append(ds, schema.TestResourceDataRaw(t, l.r.Schema, l.raw))
+	}
+
+	// The lists of one gateway are written together, and re-applied after
+	// a concurrent change, which the update of the lists then overrides.
+	api.afterGet = concurrentRoute(api, 1,
+		swagger.StaticRoute{Destination: "10.9.0.0/16", Device: "GE2", Nhop: "10.0.9.1", Cost: 1})
+	all := applyConcurrently(ds, func(d *schema.ResourceData) diag.Diagnostics {
+		for i := range ds {
+			if ds[i] == d {
+				return lists[i].r.CreateContext(context.Background(), d, client)
+			}
+		}
+		return nil
+	})
+	for _, diags := range all {
+		require.False(t, diags.HasError(), "%v", diags)
+	}
+
+	// Read, update rejected by If-Unmodified-Since, re-read, update.
+	assert.Equal(t, 2, api.count("GET"))
+	assert.Equal(t, 2, api.count("PUT"))
+	gateway := api.edge("gw1")
+	require.Len(t, gateway.StaticRoutes, 1)
+	assert.Equal(t, "10.1.0.0/16", gateway.StaticRoutes[0].Destination)
+	assert.Len(t, gateway.BgpConfiguration, 1)
+	assert.Len(t, gateway.One2OneNatRules, 1)
+	assert.Len(t, gateway.PortForwardingNatRules, 1)
+
+	all = applyConcurrently(ds, func(d *schema.ResourceData) diag.Diagnostics {
+		for i := range ds {
+			if ds[i] == d {
+				return lists[i].r.DeleteContext(context.Background(), d, client)
+			}
+		}
+		return nil
+	})
+	for _, diags := range all {
+		require.False(t, diags.HasError(), "%v", diags)
+	}
+
+	assert.Equal(t, 3, api.count("PUT"))
+	assert.JSONEq(t, `{"staticRoutes":[],"bgpConfiguration":[],"one2OneNatRules":[],"portForwardingNatRules":[]}`,
+		api.bodies[len(api.bodies)-1])
+
+	// Deleting the lists of a gateway that is gone succeeds.
+	delete(api.edges, "gw1")
+	diags := lists[0].r.DeleteContext(context.Background(), ds[0], client)
+	require.False(t, diags.HasError(), "%v", diags)
+}
+
+func TestEdgeWriterSeparatesEdgesAndFailures(t *testing.T) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"}, swagger.Edge{Id: "gw2"})
+	client.writer = newEdgeWriter(client, 200*time.Millisecond)
+
+	addRoute := func(destination string) edgeMutation {
+		return edgeMutation{
+			Lists: []string{"staticRoutes"},
+			Apply: func(gateway *swagger.Edge) error {
+				gateway.StaticRoutes = append(gateway.StaticRoutes,
+					swagger.StaticRoute{Destination: destination, Device: "GE1", Nhop: "10.0.0.1"})
+				return nil
+			},
+		}
+	}
+	errRejected := errors.New("rejected")
+
+	type write struct {
+		id       string
+		mutation edgeMutation
+	}
+	writes := []write{
+		{"gw1", addRoute("10.1.0.0/16")},
+		{"gw2", addRoute("10.2.0.0/16")},
+		{"gw1", edgeMutation{
+			Lists: []string{"bgpConfiguration"},
+			Apply: func(gateway *swagger.Edge) error { return errRejected },
+		}},
+		{"gw1", addRoute("10.3.0.0/16")},
+		{"gw3", addRoute("10.4.0.0/16")},
+	}
+
+	errs := make([]error, len(writes))
+	gateways := make([]swagger.Edge, len(writes))
+	var wg sync.WaitGroup
+	for i, w := range writes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gateways[i], errs[i] = client.writer.update(context.Background(), w.id, w.mutation)
+		}()
+	}
+	wg.Wait()
+
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.ErrorIs(t, errs[2], errRejected)
+	assert.NoError(t, errs[3])
+
+	// The unknown edge fails the GET and is reported as such.
+	diags := edgeWriteError(errs[4], nil)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "GetEdgeById failed: 404 Not Found", diags[0].Summary)
+
+	assert.Len(t, gateways[0].StaticRoutes, 2)
+	assert.Equal(t, gateways[0].StaticRoutes, gateways[3].StaticRoutes)
+	assert.Len(t, gateways[1].StaticRoutes, 1)
+
+	// One GET per edge, one PUT per existing edge, and the failed mutation
+	// did not add its list to the update.
+	assert.Equal(t, 3, api.count("GET"))
+	assert.Equal(t, 2, api.count("PUT"))
+	for _, body := range api.bodies {
+		assert.NotContains(t, body, "bgpConfiguration")
+	}
+}
+
+func TestEdgeWriterRejectedBatch(t *testing.T) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"})
+	client.writer = newEdgeWriter(client, 200*time.Millisecond)
+	api.reject = func(body string) string {
+		if strings.Contains(body, `"nhop":"10.0.0.0"`) {
+			return "invalid next hop"
+		}
+		return ""
+	}
+
+	routes := mustResource(t, resourceGatewayStaticRoute)
+	var ds []*schema.ResourceData
+	for i, nhop := range []string{"10.0.0.1", "10.0.0.0", "10.0.0.1"} {
+		route := testRoute(fmt.Sprintf("10.%d.0.0/16", i+1))
+		route["gateway_id"] = "gw1"
+		route["nhop"] = nhop
+		ds = append(ds, schema.TestResourceDataRaw(t, routes.Schema, route))
+	}
+
+	all := applyConcurrently(ds, func(d *schema.ResourceData) diag.Diagnostics {
+		return routes.CreateContext(context.Background(), d, client)
+	})
+
+	// Only the route at fault fails, the others are sent on their own.
+	assert.False(t, all[0].HasError(), "%v", all[0])
+	require.True(t, all[1].HasError())
+	assert.Equal(t, "PUT /edges/gw1: invalid next hop", all[1][0].Detail)
+	assert.False(t, all[2].HasError(), "%v", all[2])
+	assert.Equal(t, 4, api.count("PUT"))
+
+	var destinations []string
+	for _, route := range api.edge("gw1").StaticRoutes {
+		destinations = append(destinations, route.Destination)
+	}
+	assert.ElementsMatch(t, []string{"10.1.0.0/16", "10.3.0.0/16"}, destinations)
+}
+
+func TestEdgeBatchContext(t *testing.T) {
+	now := time.Now()
+	b := &edgeBatch{writes: []*edgeWrite{
+		{deadline: now.Add(time.Minute)},
+		{deadline: now.Add(time.Hour)},
+	}}
+	ctx, cancel := b.context()
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	assert.Equal(t, now.Add(time.Hour), deadline)
+
+	// A caller without a deadline waits for as long as it takes.
+	b.writes = append(b.writes, &edgeWrite{})
+	ctx, cancel = b.context()
+	defer cancel()
+	_, ok = ctx.Deadline()
+	assert.False(t, ok)
+}
+
+func TestEdgeWriterCancelledWaiter(t *testing.T) {
+	_, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"})
+	client.writer = newEdgeWriter(client, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.writer.update(ctx, "gw1", edgeMutation{
+		Apply: func(gateway *swagger.Edge) error { return nil },
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// concurrentRoute returns an afterGet hook that adds or replaces a static
+// route of the edge on the first n reads, as another client would.
+func concurrentRoute(api *fakeEdgeAPI, n int, route swagger.StaticRoute) func(edge *swagger.Edge) {
+	reads := 0
+	return func(edge *swagger.Edge) {
+		reads++
+		if reads > n {
+			return
+		}
+		index := _resourceGatewayStaticRoute{}.getExistingStaticRoute(edge.StaticRoutes, route)
+		if index >= 0 {
+			edge.StaticRoutes[index] = route
+		} else {
+			edge.StaticRoutes = append(edge.StaticRoutes, route)
+		}
+		edge.ModifiedBy = &swagger.UserRef{Email: "admin@example.com"}
+		api.modify(edge)
+	}
+}
+
+func TestEdgeWriterReappliesAfterConcurrentChange(t *testing.T) {
+	for _, noETag := range []bool{false, true} {
+		t.Run(fmt.Sprintf("noETag=%v", noETag), func(t *testing.T) {
+			api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1", DateModified: time.Unix(1700000000, 0).UTC()})
+			client.writer = newEdgeWriter(client, time.Millisecond)
+			api.noETag = noETag
+			api.afterGet = concurrentRoute(api, 1,
+				swagger.StaticRoute{Destination: "10.9.0.0/16", Device: "GE2", Nhop: "10.0.9.1", Cost: 1})
+
+			r := mustResource(t, resourceGatewayStaticRoute)
+			d := routeStates(t, r, 1)[0]
+			diags := r.CreateContext(context.Background(), d, client)
+			require.False(t, diags.HasError(), "%v", diags)
+
+			// The concurrent route survived, and ours was added to it.
+			routes := api.edge("gw1").StaticRoutes
+			require.Len(t, routes, 2)
+			assert.Equal(t, "10.9.0.0/16", routes[0].Destination)
+			assert.Equal(t, "10.1.0.0/16", routes[1].Destination)
+			assert.Len(t, api.bodies, 1)
+
+			// Read, rejected update, re-read, update.
+			assert.Equal(t, 2, api.count("GET"))
+			assert.Equal(t, 2, api.count("PUT"))
+		})
+	}
+}
+
+func TestEdgeWriterConflicts(t *testing.T) {
+	ours := swagger.StaticRoute{Destination: "10.1.0.0/16", Device: "GE1", Nhop: "10.0.0.1", Cost: 5}
+
+	api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1", Name: "branch", StaticRoutes: []swagger.StaticRoute{ours}})
+	client.writer = newEdgeWriter(client, time.Millisecond)
+
+	// Someone else changes the route this resource updates.
+	theirs := ours
+	theirs.Nhop = "10.0.0.2"
+	api.afterGet = concurrentRoute(api, 1, theirs)
+
+	r := mustResource(t, resourceGatewayStaticRoute)
+	d := routeStates(t, r, 1)[0]
+	diags := r.UpdateContext(context.Background(), d, client)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Error, diags[0].Severity)
+	assert.Equal(t, `Conflicting change to static route "10.1.0.0/16"`, diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, `Static route "10.1.0.0/16" of gateway "branch" (gw1) was modified by admin@example.com`)
+	assert.Equal(t, []swagger.StaticRoute{theirs}, api.edge("gw1").StaticRoutes)
+	assert.Empty(t, api.bodies)
+
+	// An edge that keeps changing is given up on.
+	api.afterGet = concurrentRoute(api, 100,
+		swagger.StaticRoute{Destination: "10.9.0.0/16", Device: "GE2", Nhop: "10.0.9.1", Cost: 1})
+	d = routeStates(t, r, 2)[1]
+	diags = r.CreateContext(context.Background(), d, client)
+	require.Len(t, diags, 1)
+	assert.Equal(t, `Gateway "branch" keeps changing`, diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "on each of 4 attempts")
+	assert.Empty(t, api.bodies)
+}
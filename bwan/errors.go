@@ -0,0 +1,203 @@
+package bwan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// statusError is implemented by swagger.GenericSwaggerError and
+// apiStatusError, the errors of requests that got a non-2xx response.
+type statusError interface {
+	error
+	Body() []byte
+}
+
+var _ statusError = swagger.GenericSwaggerError{}
+
+// isNotFound reports whether an API call failed because the object at path,
+// e.g. "/edges/gw1", does not exist: the request for that path under the
+// base URL got a 404. A 404 for another path, e.g. after a redirect of a
+// wrong base URL, and every other status are errors like any other, so that
+// a misconfigured provider does not plan to re-create everything.
+func (c *apiClient) isNotFound(err error, resp *http.Response, path string) bool {
+	if err == nil || resp == nil || resp.StatusCode != http.StatusNotFound || resp.Request == nil {
+		return false
+	}
+
+	base, perr := url.Parse(c.cfg.BasePath)
+	if perr != nil {
+		return false
+	}
+	return resp.Request.URL.Path == strings.TrimSuffix(base.Path, "/")+path
+}
+
+// apiErrorBody covers the error bodies returned by the orchestrator: the
+// documented InfiotErrorResponse, optionally naming the offending field
+// directly or through a list of validation errors.
+type apiErrorBody struct {
+	Message string          `json:"message"`
+	Field   json.RawMessage `json:"field"`
+	Path    json.RawMessage `json:"path"`
+	Errors  []apiFieldError `json:"errors"`
+	Details []apiFieldError `json:"details"`
+}
+
+type apiFieldError struct {
+	Message string          `json:"message"`
+	Field   json.RawMessage `json:"field"`
+	Path    json.RawMessage `json:"path"`
+}
+
+// apiError translates a failed API call into diagnostics carrying the
+// operation, HTTP method, resource path and status. Fields named by the error
+// body are mapped back to schema keys through the binder so that the
+// diagnostic points at the offending attribute.
+func apiError(op string, resp *http.Response, err error, bm []FieldBinder) diag.Diagnostics {
+	serr, ok := err.(statusError)
+	if !ok {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("%s failed", op),
+				Detail:   requestLine(resp) + err.Error(),
+			},
+		}
+	}
+
+	status := serr.Error()
+	if resp != nil {
+		status = resp.Status
+	}
+	summary := fmt.Sprintf("%s failed: %s", op, status)
+
+	var body apiErrorBody
+	if json.Unmarshal(serr.Body(), &body) != nil {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  summary,
+				Detail:   requestLine(resp) + strings.TrimSpace(string(serr.Body())),
+			},
+		}
+	}
+
+	var diags diag.Diagnostics
+	for _, fe := range append(body.Errors, body.Details...) {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       summary,
+			Detail:        requestLine(resp) + fe.Message,
+			AttributePath: attributePath(bm, fe.Field, fe.Path),
+		})
+	}
+
+	if body.Message != "" || len(diags) == 0 {
+		diags = append(diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       summary,
+				Detail:        requestLine(resp) + body.Message,
+				AttributePath: attributePath(bm, body.Field, body.Path),
+			},
+		}, diags...)
+	}
+
+	return diags
+}
+
+// edgeWriteError translates an error of edgeWriter.update, or of a poll of
+// an edge, into diagnostics.
+func edgeWriteError(err error, bm []FieldBinder) diag.Diagnostics {
+	var aerr edgeAPIError
+	if errors.As(err, &aerr) {
+		return apiError(aerr.op, aerr.resp, aerr.err, bm)
+	}
+
+	var cerr edgeConflictError
+	if errors.As(err, &cerr) {
+		return changedConcurrently(cerr)
+	}
+
+	return diag.FromErr(err)
+}
+
+func requestLine(resp *http.Response) string {
+	if resp == nil || resp.Request == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s: ", resp.Request.Method, resp.Request.URL.Path)
+}
+
+var matchPathSegment = regexp.MustCompile(`[^.\[\]]+`)
+
+// attributePath maps an API field reference such as "remoteAS" or
+// "interfaces[1].addresses" to the schema attribute path. The reference is
+// given either as a dotted string or as a list of keys and indices.
+func attributePath(bm []FieldBinder, refs ...json.RawMessage) cty.Path {
+	for _, ref := range refs {
+		var segments []string
+
+		var s string
+		var l []interface{}
+		if json.Unmarshal(ref, &s) == nil {
+			segments = matchPathSegment.FindAllString(s, -1)
+		} else if json.Unmarshal(ref, &l) == nil {
+			for _, v := range l {
+				segments = append(segments, fmt.Sprint(v))
+			}
+		}
+
+		if p := bindPath(bm, segments); p != nil {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func bindPath(bm []FieldBinder, segments []string) cty.Path {
+	hasKey := func(segment string) (string, bool) {
+		key := toSnakeCase(segment)
+		for _, b := range bm {
+			if b.MapKey == key {
+				return key, true
+			}
+		}
+		return "", false
+	}
+
+	if len(segments) == 0 {
+		return nil
+	}
+
+	if key, ok := hasKey(segments[0]); ok {
+		p := cty.GetAttrPath(key)
+		for _, segment := range segments[1:] {
+			if i, err := strconv.Atoi(segment); err == nil {
+				p = p.IndexInt(i)
+			} else {
+				p = p.GetAttr(toSnakeCase(segment))
+			}
+		}
+		return p
+	}
+
+	// Sub-resources embed a single item of an edge list, for which the API
+	// reports the field within the list, e.g. "bgpConfiguration[0].remoteAS".
+	if key, ok := hasKey(segments[len(segments)-1]); ok {
+		return cty.GetAttrPath(key)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package bwan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingClient returns a client whose every request is answered with the
+// given status and body.
+func failingClient(t *testing.T, status int, body string) *apiClient {
+	return testAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestApiError(t *testing.T) {
+	_, binder, _, err := ReflectSchema(resourceGatewayBgpInput{}, Cfg{})
+	require.NoError(t, err)
+
+	t.Run("field errors", func(t *testing.T) {
+		client := failingClient(t, http.StatusBadRequest,
+			`{"message":"invalid configuration","errors":[{"field":"bgpConfiguration[0].remoteAS","message":"out of range"}]}`)
+
+		_, resp, err := client.EdgesApi.UpdateEdgeById(context.Background(), swagger.UpdateEdgeInput{}, "gw1", nil)
+		require.Error(t, err)
+
+		diags := apiError("UpdateEdgeById", resp, err, binder)
+		require.Len(t, diags, 2)
+
+		assert.Equal(t, diag.Error, diags[0].Severity)
+		assert.Equal(t, "UpdateEdgeById failed: 400 Bad Request", diags[0].Summary)
+		assert.Equal(t, "PUT /edges/gw1: invalid configuration", diags[0].Detail)
+		assert.Nil(t, diags[0].AttributePath)
+
+		assert.Equal(t, "PUT /edges/gw1: out of range", diags[1].Detail)
+		assert.Equal(t, cty.GetAttrPath("remote_as"), diags[1].AttributePath)
+	})
+
+	t.Run("plain body", func(t *testing.T) {
+		client := failingClient(t, http.StatusInternalServerError, "upstream unavailable\n")
+
+		_, resp, err := client.EdgesApi.GetEdgeById(context.Background(), "gw1", nil)
+		require.Error(t, err)
+
+		diags := apiError("GetEdgeById", resp, err, binder)
+		require.Len(t, diags, 1)
+		assert.Equal(t, "GetEdgeById failed: 500 Internal Server Error", diags[0].Summary)
+		assert.Equal(t, "GET /edges/gw1: upstream unavailable", diags[0].Detail)
+	})
+
+	t.Run("transport error", func(t *testing.T) {
+		diags := apiError("GetEdgeById", nil, errors.New("connection refused"), binder)
+		require.Len(t, diags, 1)
+		assert.Equal(t, "GetEdgeById failed", diags[0].Summary)
+		assert.Equal(t, "connection refused", diags[0].Detail)
+	})
+}
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{http.StatusNotFound, ``, true},
+		{http.StatusNotFound, `{"message":"edge not found"}`, true},
+		// Not found messages with other statuses are not taken as gone.
+		{http.StatusBadRequest, `{"message":"edge not found"}`, false},
+		{http.StatusInternalServerError, `{"message":"Policy not found."}`, false},
+		{http.StatusBadRequest, `{"message":"invalid configuration"}`, false},
+	}
+	for _, test := range tests {
+		client := failingClient(t, test.status, test.body)
+
+		_, resp, err := client.EdgesApi.GetEdgeById(context.Background(), "gw1", nil)
+		require.Error(t, err)
+		assert.Equal(t, test.want, client.isNotFound(err, resp, "/edges/gw1"), "%d %s", test.status, test.body)
+		assert.False(t, client.isNotFound(err, resp, "/edges/gw2"), "%d %s", test.status, test.body)
+	}
+
+	client := failingClient(t, http.StatusNotFound, ``)
+	assert.False(t, client.isNotFound(nil, nil, "/edges/gw1"))
+	assert.False(t, client.isNotFound(errors.New("edge not found"), nil, "/edges/gw1"))
+
+	t.Run("base path", func(t *testing.T) {
+		api, _ := newFakeEdgeAPI(t)
+		client := cachedAPIClient(t, http.StripPrefix("/v1", api))
+
+		_, resp, err := client.EdgesApi.GetEdgeById(context.Background(), "gw1", nil)
+		require.Error(t, err)
+		assert.True(t, client.isNotFound(err, resp, "/edges/gw1"))
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		// A wrong base URL redirected to a page that does not exist.
+		client := testAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/login" {
+				http.NotFound(w, r)
+				return
+			}
+			http.Redirect(w, r, "/login", http.StatusFound)
+		}))
+
+		_, resp, err := client.EdgesApi.GetEdgeById(context.Background(), "gw1", nil)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.False(t, client.isNotFound(err, resp, "/edges/gw1"))
+	})
+}
+
+func TestAttributePath(t *testing.T) {
+	_, binder, _, err := ReflectSchema(swagger.Edge{}, Cfg{})
+	require.NoError(t, err)
+
+	raw := func(v interface{}) json.RawMessage {
+		b, _ := json.Marshal(v)
+		return b
+	}
+
+	assert.Equal(t,
+		cty.GetAttrPath("interfaces").IndexInt(1).GetAttr("addresses"),
+		attributePath(binder, raw("interfaces[1].addresses")))
+	assert.Equal(t,
+		cty.GetAttrPath("interfaces").IndexInt(0).GetAttr("mtu"),
+		attributePath(binder, raw([]interface{}{"interfaces", 0, "mtu"})))
+	assert.Equal(t,
+		cty.GetAttrPath("name"),
+		attributePath(binder, nil, raw("name")))
+	assert.Nil(t, attributePath(binder, raw("doesNotExist")))
+}
@@ -0,0 +1,168 @@
+package bwan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+)
+
+// fakeEdgeAPI is a minimal in-memory orchestrator serving GET and PUT of
+// single edges, their activation and their last known status. PUT merges the
+// fields present in the body into the edge, as the orchestrator does, and
+// updates the edge's modification date. Edges are served with an ETag, which
+// a PUT with If-Match must match, or with noETag, only with the modification
+// date, which a PUT with If-Unmodified-Since must not be after.
+type fakeEdgeAPI struct {
+	mu       sync.Mutex
+	edges    map[string]*swagger.Edge
+	versions map[string]int
+	// statuses are the last known statuses of edges. Edges without one
+	// have not reported yet.
+	statuses map[string]*swagger.EdgeStatusRef
+
+	// noETag turns off ETags and If-Match.
+	noETag bool
+	// afterGet, if set, is called with the edge after every GET of it or
+	// its status, e.g. to simulate a concurrent change through modify.
+	afterGet func(edge *swagger.Edge)
+	// reject, if set, is called with the body of every PUT, which is
+	// rejected with a 400 and the returned message unless it is empty.
+	reject func(body string) string
+
+	// requests lists "METHOD path" of every request in order.
+	requests []string
+	// bodies lists the bodies of PUT requests in order.
+	bodies []string
+}
+
+func newFakeEdgeAPI(t *testing.T, edges ...swagger.Edge) (*fakeEdgeAPI, *apiClient) {
+	f := &fakeEdgeAPI{
+		edges:    map[string]*swagger.Edge{},
+		versions: map[string]int{},
+		statuses: map[string]*swagger.EdgeStatusRef{},
+	}
+	for i := range edges {
+		f.edges[edges[i].Id] = &edges[i]
+	}
+
+	return f, testAPIClient(t, f)
+}
+
+func (f *fakeEdgeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	id, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/edges/"), "/")
+	edge, ok := f.edges[id]
+	if !ok || (sub == "status" && f.statuses[id] == nil) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"edge not found"}`))
+		return
+	}
+
+	switch {
+	case sub == "activate" && r.Method == http.MethodPost:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(swagger.EdgeActivationCode{Token: "token-" + id})
+		return
+	case sub == "status" && r.Method == http.MethodGet:
+		if f.afterGet != nil {
+			defer f.afterGet(edge)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(f.statuses[id])
+		return
+	case sub != "":
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"not found"}`))
+		return
+	case r.Method == http.MethodGet:
+		if f.afterGet != nil {
+			defer f.afterGet(edge)
+		}
+	case r.Method == http.MethodPut:
+		if !f.unmodified(r, edge) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			w.Write([]byte(`{"message":"edge was modified"}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		f.bodies = append(f.bodies, string(body))
+		if f.reject != nil {
+			if message := f.reject(string(body)); message != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"message":"` + message + `"}`))
+				return
+			}
+		}
+		if err := json.Unmarshal(body, edge); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"` + err.Error() + `"}`))
+			return
+		}
+		f.modify(edge)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !f.noETag {
+		w.Header().Set("ETag", f.etag(id))
+	}
+	json.NewEncoder(w).Encode(edge)
+}
+
+// unmodified reports whether the conditions of the request, If-Match or
+// without ETags If-Unmodified-Since, hold for the edge.
+func (f *fakeEdgeAPI) unmodified(r *http.Request, edge *swagger.Edge) bool {
+	if f.noETag {
+		since, err := http.ParseTime(r.Header.Get("If-Unmodified-Since"))
+		return err != nil || !edge.DateModified.Truncate(time.Second).After(since)
+	}
+
+	match := r.Header.Get("If-Match")
+	return match == "" || match == f.etag(edge.Id)
+}
+
+func (f *fakeEdgeAPI) etag(id string) string {
+	return fmt.Sprintf(`"%d"`, f.versions[id])
+}
+
+// modify records a change of the edge. It must be called with f.mu held,
+// which is the case in afterGet.
+func (f *fakeEdgeAPI) modify(edge *swagger.Edge) {
+	f.versions[edge.Id]++
+	edge.DateModified = time.Unix(1700000000, 0).Add(time.Duration(f.versions[edge.Id]) * time.Second).UTC()
+}
+
+// edge returns a copy of the stored edge.
+func (f *fakeEdgeAPI) edge(id string) swagger.Edge {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return *f.edges[id]
+}
+
+// count returns the number of requests with the given method.
+func (f *fakeEdgeAPI) count(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, r := range f.requests {
+		if strings.HasPrefix(r, method+" ") {
+			n++
+		}
+	}
+	return n
+}
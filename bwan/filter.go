@@ -0,0 +1,71 @@
+package bwan
+
+import (
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// nameRegexSchema is the name filter shared by the list data sources.
+func nameRegexSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Description:      "Only return objects whose name matches this regular expression.",
+		ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+	}
+}
+
+// idsSchema lists the IDs of the objects matched by a list data source, in
+// the order they are returned by the API.
+func idsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "IDs of the matching objects.",
+		Elem:        &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+// nameRegex returns the compiled name_regex argument, or nil if it is not set.
+func nameRegex(d *schema.ResourceData) (*regexp.Regexp, error) {
+	v, ok := d.GetOk("name_regex")
+	if !ok {
+		return nil, nil
+	}
+
+	return regexp.Compile(v.(string))
+}
+
+// optionalBool returns a boolean argument and whether it is set in the
+// configuration, so that false can be used as a filter value.
+func optionalBool(d *schema.ResourceData, key string) (bool, bool) {
+	if raw := d.GetRawConfig(); !raw.IsNull() {
+		v := raw.GetAttr(key)
+		if v.IsNull() || !v.IsKnown() {
+			return false, false
+		}
+
+		return v.True(), true
+	}
+
+	// The raw configuration is not available outside of Terraform operations,
+	// e.g. in unit tests.
+	v, ok := d.GetOkExists(key) //nolint:staticcheck
+	if !ok {
+		return false, false
+	}
+
+	return v.(bool), true
+}
+
+// stringList returns a list of strings argument.
+func stringList(d *schema.ResourceData, key string) []string {
+	var l []string
+	for _, v := range d.Get(key).([]interface{}) {
+		l = append(l, v.(string))
+	}
+
+	return l
+}
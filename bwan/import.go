@@ -0,0 +1,76 @@
+package bwan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// importStateComposite returns an importer for resources addressed by a
+// composite ID of the form "<key1>/<key2>/...". Each part is written to the
+// matching schema key. The last key receives the remainder of the ID, so
+// values such as CIDR destinations may themselves contain "/".
+func importStateComposite(keys ...string) schema.StateContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+		parts := strings.SplitN(d.Id(), "/", len(keys))
+		if len(parts) != len(keys) {
+			return nil, fmt.Errorf("unexpected import ID %q, expected %s",
+				d.Id(), "<"+strings.Join(keys, ">/<")+">")
+		}
+
+		for i, key := range keys {
+			if parts[i] == "" {
+				return nil, fmt.Errorf("unexpected import ID %q: %s is empty", d.Id(), key)
+			}
+			if err := d.Set(key, parts[i]); err != nil {
+				return nil, fmt.Errorf("%v: %w", key, err)
+			}
+		}
+
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
+// compositeId builds the natural-key ID of a gateway sub-resource, the inverse
+// of importStateComposite.
+func compositeId(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// upgradeStateCompositeId migrates version 0 states, whose IDs were a hash of
+// the object, to the composite ID built from the given keys. States missing
+// one of the keys, such as NAT rules created before their name was
+// required, keep their old ID until the key is set by the next apply.
+func upgradeStateCompositeId(keys ...string) schema.StateUpgradeFunc {
+	return func(ctx context.Context, rawState map[string]interface{}, m interface{}) (map[string]interface{}, error) {
+		if rawState == nil {
+			return rawState, nil
+		}
+
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			v, _ := rawState[key].(string)
+			if v == "" {
+				return rawState, nil
+			}
+			parts = append(parts, v)
+		}
+
+		rawState["id"] = compositeId(parts...)
+		return rawState, nil
+	}
+}
+
+// compositeIdStateUpgraders returns the state upgraders for a sub-resource
+// whose schema was unchanged when it moved to composite IDs.
+func compositeIdStateUpgraders(s map[string]*schema.Schema, keys ...string) []schema.StateUpgrader {
+	return []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    (&schema.Resource{Schema: s}).CoreConfigSchema().ImpliedType(),
+			Upgrade: upgradeStateCompositeId(keys...),
+		},
+	}
+}
@@ -0,0 +1,158 @@
+package bwan
+
+import (
+	"context"
+	"testing"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestUpgradeStateCompositeId(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		in   m
+		out  m
+	}{
+		{"bgp", []string{"gateway_id", "neighbor"}, m{
+			"id":         "0123456789abcdef0123456789abcdef",
+			"gateway_id": "gw1",
+			"neighbor":   "169.254.1.1",
+		}, m{
+			"id":         "gw1/169.254.1.1",
+			"gateway_id": "gw1",
+			"neighbor":   "169.254.1.1",
+		}},
+		{"static route", []string{"gateway_id", "destination"}, m{
+			"id":          "0123456789abcdef0123456789abcdef",
+			"gateway_id":  "gw1",
+			"destination": "10.0.0.0/24",
+		}, m{
+			"id":          "gw1/10.0.0.0/24",
+			"gateway_id":  "gw1",
+			"destination": "10.0.0.0/24",
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := upgradeStateCompositeId(test.keys...)(context.Background(), test.in, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.out, out)
+		})
+	}
+
+	// NAT rules created before names were required keep their ID.
+	out, err := upgradeStateCompositeId("gateway_id", "name")(context.Background(), m{
+		"id":         "0123456789abcdef0123456789abcdef",
+		"gateway_id": "gw1",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, m{
+		"id":         "0123456789abcdef0123456789abcdef",
+		"gateway_id": "gw1",
+	}, out)
+}
+
+func TestUpgradedNatRuleWithoutName(t *testing.T) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{
+		Id: "gw1",
+		PortForwardingNatRules: []swagger.InboundNatRule{
+			{PublicIp: "1.1.1.3", PublicPort: 22, UpLinkIfName: "GE1", LanIp: "192.168.1.10", LanPort: 8080},
+		},
+	})
+	r := mustResource(t, resourceGatewayPortForward)
+
+	// The state of a rule created before names were required.
+	config := testPortForwardConfig("gw1")
+	delete(config, "name")
+	d := schema.TestResourceDataRaw(t, r.Schema, config)
+	d.SetId("0123456789abcdef0123456789abcdef")
+
+	diags := r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef", d.Id())
+
+	// Naming it renames the rule instead of adding another one.
+	diff, err := r.SimpleDiff(context.Background(), d.State(),
+		terraform.NewResourceConfigRaw(testPortForwardConfig("gw1")), client)
+	require.NoError(t, err)
+	state, diags := r.Apply(context.Background(), d.State(), diff, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, "gw1/ssh", state.ID)
+	rules := api.edge("gw1").PortForwardingNatRules
+	require.Len(t, rules, 1)
+	assert.Equal(t, "ssh", rules[0].Name)
+}
+
+func TestUpgradedNatRulesWithoutName(t *testing.T) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{
+		Id: "gw1",
+		PortForwardingNatRules: []swagger.InboundNatRule{
+			{PublicIp: "1.1.1.3", PublicPort: 22, UpLinkIfName: "GE1", LanIp: "192.168.1.10", LanPort: 22},
+			{PublicIp: "1.1.1.3", PublicPort: 23, UpLinkIfName: "GE1", LanIp: "192.168.1.10", LanPort: 23},
+		},
+	})
+	r := mustResource(t, resourceGatewayPortForward)
+
+	// The states of two rules created before names were required.
+	config := func(name string, port int) map[string]interface{} {
+		config := testPortForwardConfig("gw1")
+		config["public_port"] = port
+		config["lan_port"] = port
+		if name == "" {
+			delete(config, "name")
+		} else {
+			config["name"] = name
+		}
+		return config
+	}
+	ssh := schema.TestResourceDataRaw(t, r.Schema, config("", 22))
+	ssh.SetId("00000000000000000000000000000022")
+	telnet := schema.TestResourceDataRaw(t, r.Schema, config("", 23))
+	telnet.SetId("00000000000000000000000000000023")
+
+	// Each reads its own rule.
+	for _, d := range []*schema.ResourceData{ssh, telnet} {
+		diags := r.ReadContext(context.Background(), d, client)
+		require.False(t, diags.HasError(), "%v", diags)
+	}
+	assert.Equal(t, 22, ssh.Get("lan_port"))
+	assert.Equal(t, 23, telnet.Get("lan_port"))
+
+	// Naming the second one renames it and leaves the first one alone.
+	diff, err := r.SimpleDiff(context.Background(), telnet.State(),
+		terraform.NewResourceConfigRaw(config("telnet", 23)), client)
+	require.NoError(t, err)
+	state, diags := r.Apply(context.Background(), telnet.State(), diff, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, "gw1/telnet", state.ID)
+	assert.Equal(t, []swagger.InboundNatRule{
+		{PublicIp: "1.1.1.3", PublicPort: 22, UpLinkIfName: "GE1", LanIp: "192.168.1.10", LanPort: 22},
+		{Name: "telnet", PublicIp: "1.1.1.3", PublicPort: 23, UpLinkIfName: "GE1", LanIp: "192.168.1.10", LanPort: 23},
+	}, api.edge("gw1").PortForwardingNatRules)
+}
+
+func TestImportStateComposite(t *testing.T) {
+	r := mustResource(t, resourceGatewayStaticRoute)
+	d := r.TestResourceData()
+	d.SetId("gw1/10.0.0.0/24")
+
+	out, err := importStateComposite("gateway_id", "destination")(context.Background(), d, nil)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+
+	assert.Equal(t, "gw1", out[0].Get("gateway_id"))
+	assert.Equal(t, "10.0.0.0/24", out[0].Get("destination"))
+
+	d.SetId("gw1")
+	_, err = importStateComposite("gateway_id", "destination")(context.Background(), d, nil)
+	assert.Error(t, err)
+}
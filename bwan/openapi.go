@@ -0,0 +1,90 @@
+package bwan
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+//go:generate go run -C ../tools/openapi . -dir ../.. -o ../../bwan/openapi.json
+
+// openAPIJSON holds the field descriptions, enum values and defaults of the
+// API client's OpenAPI spec, keyed by schema and JSON property name.
+//
+//go:embed openapi.json
+var openAPIJSON []byte
+
+type openAPIField struct {
+	Description string   `json:"description"`
+	Enum        []string `json:"enum"`
+	Default     string   `json:"default"`
+}
+
+// openAPIFields returns the parsed openAPIJSON. A file that does not parse
+// fails the schemas described by it instead of the plugin.
+var openAPIFields = sync.OnceValues(func() (map[string]map[string]openAPIField, error) {
+	return parseOpenAPIFields(openAPIJSON)
+})
+
+func parseOpenAPIFields(data []byte) (map[string]map[string]openAPIField, error) {
+	var fields map[string]map[string]openAPIField
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("openapi.json: %w", err)
+	}
+	return fields, nil
+}
+
+// openAPIDescription returns the description of the field of the client
+// type t in the OpenAPI spec, or "" if the spec does not document it. The
+// schemas are matched by name, ignoring case and underscores.
+func openAPIDescription(t reflect.Type, field reflect.StructField) (string, error) {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+	f, ok, err := openAPIProperty(t.Name(), name)
+	if !ok || err != nil {
+		return "", err
+	}
+
+	var parts []string
+	if f.Description != "" {
+		parts = append(parts, f.Description)
+	}
+	if len(f.Enum) > 0 {
+		parts = append(parts, "One of "+codeList(f.Enum)+".")
+	}
+	if f.Default != "" {
+		parts = append(parts, fmt.Sprintf("The orchestrator defaults to `%s`.", f.Default))
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// openAPIProperty returns the property of the schema of the client type
+// named typeName, matched ignoring case and underscores.
+func openAPIProperty(typeName, property string) (openAPIField, bool, error) {
+	fields, err := openAPIFields()
+	if err != nil {
+		return openAPIField{}, false, err
+	}
+
+	schema := strings.ToLower(strings.ReplaceAll(typeName, "_", ""))
+	f, ok := fields[schema][property]
+
+	return f, ok, nil
+}
+
+// codeList formats values as "`a`, `b` or `c`".
+func codeList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "`" + v + "`"
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
@@ -0,0 +1,87 @@
+package bwan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+
+	"github.com/antihax/optional"
+)
+
+// listPageSize is the number of items requested per page from the cursor
+// paginated list endpoints. The API defaults to 20.
+const listPageSize = 200
+
+// listPage is a single page returned by a cursor paginated list endpoint.
+type listPage[T any] struct {
+	Data      []T
+	LastPage  bool
+	EndCursor string
+}
+
+// paginate walks all pages returned by fetch, calling fn for every item in
+// order. It stops early, without fetching further pages, when fn returns
+// false. The response of the last request is returned for error reporting.
+func paginate[T any](
+	ctx context.Context,
+	fetch func(ctx context.Context, after string) (listPage[T], *http.Response, error),
+	fn func(T) bool) (*http.Response, error) {
+
+	var after string
+	seen := map[string]bool{}
+
+	for {
+		page, resp, err := fetch(ctx, after)
+		if err != nil {
+			return resp, err
+		}
+
+		for _, item := range page.Data {
+			if !fn(item) {
+				return resp, nil
+			}
+		}
+
+		if page.LastPage || len(page.Data) == 0 {
+			return resp, nil
+		}
+
+		// Guard against a server that does not advance the cursor, which
+		// would otherwise make us loop forever.
+		if page.EndCursor == "" || seen[page.EndCursor] {
+			return resp, fmt.Errorf("pagination did not advance past cursor %q", page.EndCursor)
+		}
+		seen[page.EndCursor] = true
+		after = page.EndCursor
+	}
+}
+
+// forEachEdge calls fn for every edge of the tenant.
+func forEachEdge(ctx context.Context, apiSvc *apiClient,
+	fn func(swagger.Edge) bool) (*http.Response, error) {
+	return paginate(ctx, func(ctx context.Context, after string) (listPage[swagger.Edge], *http.Response, error) {
+		opts := swagger.EdgesApiGetAllEdgesOpts{MaxItems: optional.NewInt32(listPageSize)}
+		if after != "" {
+			opts.AfterCursor = optional.NewString(after)
+		}
+
+		l, resp, err := apiSvc.EdgesApi.GetAllEdges(ctx, &opts)
+		return listPage[swagger.Edge]{Data: l.Data, LastPage: l.LastPage, EndCursor: l.EndCursor}, resp, err
+	}, fn)
+}
+
+// forEachTenant calls fn for every tenant visible to the token.
+func forEachTenant(ctx context.Context, apiSvc *apiClient,
+	fn func(swagger.Tenant) bool) (*http.Response, error) {
+	return paginate(ctx, func(ctx context.Context, after string) (listPage[swagger.Tenant], *http.Response, error) {
+		opts := swagger.TenantsApiGetAllTenantsOpts{MaxItems: optional.NewInt32(listPageSize)}
+		if after != "" {
+			opts.AfterCursor = optional.NewString(after)
+		}
+
+		l, resp, err := apiSvc.TenantsApi.GetAllTenants(ctx, &opts)
+		return listPage[swagger.Tenant]{Data: l.Data, LastPage: l.LastPage, EndCursor: l.EndCursor}, resp, err
+	}, fn)
+}
@@ -0,0 +1,150 @@
+package bwan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// pagingServer serves n edges and tenants in cursor paginated pages, the
+// cursor being the index of the last item returned. The tenants are spread
+// over three parents. It records the query of every request.
+func pagingServer(t *testing.T, n int) (*apiClient, *[]string) {
+	var queries []string
+
+	client := testAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+
+		maxItems := 20
+		if v := r.URL.Query().Get("maxItems"); v != "" {
+			maxItems, _ = strconv.Atoi(v)
+		}
+		start := 0
+		if v := r.URL.Query().Get("afterCursor"); v != "" {
+			after, _ := strconv.Atoi(v)
+			start = after + 1
+		}
+		end := start + maxItems
+		if end > n {
+			end = n
+		}
+
+		page := map[string]interface{}{
+			"firstPage":   start == 0,
+			"lastPage":    end == n,
+			"startCursor": strconv.Itoa(start),
+			"endCursor":   strconv.Itoa(end - 1),
+		}
+
+		var data []interface{}
+		for i := start; i < end; i++ {
+			switch r.URL.Path {
+			case "/edges":
+				data = append(data, swagger.Edge{Id: strconv.Itoa(i), Name: fmt.Sprintf("gw-%d", i)})
+			case "/tenants":
+				data = append(data, swagger.Tenant{
+					Id: strconv.Itoa(i), Name: fmt.Sprintf("tenant-%d", i), ParentId: fmt.Sprintf("parent-%d", i%3),
+				})
+			}
+		}
+		page["data"] = data
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}))
+
+	return client, &queries
+}
+
+func TestPaginateEdges(t *testing.T) {
+	client, queries := pagingServer(t, 2*listPageSize+5)
+
+	var ids []string
+	_, err := forEachEdge(context.Background(), client, func(gw swagger.Edge) bool {
+		ids = append(ids, gw.Id)
+		return true
+	})
+	require.NoError(t, err)
+
+	require.Len(t, ids, 2*listPageSize+5)
+	for i, id := range ids {
+		assert.Equal(t, strconv.Itoa(i), id)
+	}
+	assert.Equal(t, []string{
+		"maxItems=200",
+		"afterCursor=199&maxItems=200",
+		"afterCursor=399&maxItems=200",
+	}, *queries)
+}
+
+func TestPaginateTenantsStopsEarly(t *testing.T) {
+	client, queries := pagingServer(t, 3*listPageSize)
+
+	var found swagger.Tenant
+	_, err := forEachTenant(context.Background(), client, func(tenant swagger.Tenant) bool {
+		if tenant.Name == "tenant-250" {
+			found = tenant
+			return false
+		}
+		return true
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "250", found.Id)
+	assert.Len(t, *queries, 2)
+}
+
+func TestPaginateEmpty(t *testing.T) {
+	client, queries := pagingServer(t, 0)
+
+	calls := 0
+	_, err := forEachEdge(context.Background(), client, func(gw swagger.Edge) bool {
+		calls++
+		return true
+	})
+	require.NoError(t, err)
+
+	assert.Zero(t, calls)
+	assert.Len(t, *queries, 1)
+}
+
+func TestPaginateStuckCursor(t *testing.T) {
+	_, err := paginate(context.Background(),
+		func(ctx context.Context, after string) (listPage[int], *http.Response, error) {
+			return listPage[int]{Data: []int{1}, EndCursor: "same"}, nil, nil
+		}, func(int) bool { return true })
+
+	assert.ErrorContains(t, err, `cursor "same"`)
+}
+
+func TestDataSourceGatewayReadByName(t *testing.T) {
+	client, queries := pagingServer(t, 2*listPageSize)
+
+	ds := mustResource(t, dataSourceGateway)
+	d := schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"name": "gw-210",
+	})
+
+	diags := ds.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, "210", d.Id())
+	assert.Len(t, *queries, 2)
+
+	d = schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"name": "missing",
+	})
+
+	diags = ds.ReadContext(context.Background(), d, client)
+	require.True(t, diags.HasError())
+	assert.Equal(t, `gateway "missing" not found`, diags[0].Summary)
+}
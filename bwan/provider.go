@@ -1,12 +1,86 @@
 package bwan
 
 import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
 )
 
 // Provider - Netskope APIv2 Provider
 func Provider() *schema.Provider {
+	return newProvider(map[string]resourceFunc{
+		"netskopebwan_tenant":                     resourceTenant,
+		"netskopebwan_user":                       resourceUser,
+		"netskopebwan_gateway":                    resourceGateway,
+		"netskopebwan_gateway_interface":          resourceGatewayInterface,
+		"netskopebwan_gateway_bgpconfig":          resourceGatewayBgp,
+		"netskopebwan_gateway_bgp_peers":          resourceGatewayBgpPeers,
+		"netskopebwan_gateway_nat":                resourceGatewayNat,
+		"netskopebwan_gateway_nat_rules":          resourceGatewayNatRules,
+		"netskopebwan_gateway_port_forward":       resourceGatewayPortForward,
+		"netskopebwan_gateway_port_forward_rules": resourceGatewayPortForwardRules,
+		"netskopebwan_gateway_staticroute":        resourceGatewayStaticRoute,
+		"netskopebwan_gateway_static_routes":      resourceGatewayStaticRoutes,
+		"netskopebwan_policy":                     resourcePolicy,
+		"netskopebwan_gateway_activate":           resourceGatewayActivate,
+		"netskopebwan_gateway_software":           resourceGatewaySoftware,
+		"netskopebwan_gateway_rollout":            resourceGatewayRollout,
+	}, map[string]resourceFunc{
+		"netskopebwan_tenant":               dataSourceTenant,
+		"netskopebwan_tenants":              dataSourceTenants,
+		"netskopebwan_user":                 dataSourceUser,
+		"netskopebwan_users":                dataSourceUsers,
+		"netskopebwan_gateway":              dataSourceGateway,
+		"netskopebwan_gateways":             dataSourceGateways,
+		"netskopebwan_gateway_interface":    dataSourceGatewayInterface,
+		"netskopebwan_gateway_bgpconfig":    dataSourceGatewayBgp,
+		"netskopebwan_gateway_nat":          dataSourceGatewayNat,
+		"netskopebwan_gateway_port_forward": dataSourceGatewayPortForward,
+		"netskopebwan_gateway_staticroute":  dataSourceGatewayStaticRoute,
+		"netskopebwan_gateway_bootstrap":    dataSourceGatewayBootstrap,
+		"netskopebwan_software_versions":    dataSourceSoftwareVersions,
+		"netskopebwan_policy":               dataSourcePolicy,
+		"netskopebwan_policies":             dataSourcePolicies,
+	})
+}
+
+// resourceFunc builds the schema of a resource or data source, failing if
+// it cannot be reflected from the API client types.
+type resourceFunc func() (*schema.Resource, error)
+
+func newProvider(resources, dataSources map[string]resourceFunc) *schema.Provider {
+	// A schema that cannot be reflected, e.g. after an API client upgrade,
+	// must not crash the plugin. Its resource is replaced by one failing
+	// every operation, and the provider fails to configure.
+	var diags diag.Diagnostics
+	build := func(kind string, funcs map[string]resourceFunc) map[string]*schema.Resource {
+		m := make(map[string]*schema.Resource, len(funcs))
+		for _, name := range slices.Sorted(maps.Keys(funcs)) {
+			r, err := funcs[name]()
+			if err != nil {
+				d := diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  fmt.Sprintf("Invalid schema of %s %s", kind, name),
+					Detail:   err.Error(),
+				}
+				diags = append(diags, d)
+				r = brokenResource(kind, d)
+			}
+			m[name] = r
+		}
+
+		return m
+	}
+
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"baseurl": {
@@ -20,42 +94,121 @@ func Provider() *schema.Provider {
 				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("NS_SDWAN_MGMT_TOKEN", nil),
 			},
+			"max_retries": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          4,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+				Description:      "Maximum number of retries for throttled (429) or failed (5xx, connection error) API requests.",
+			},
+			"retry_wait_min": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "1s",
+				ValidateDiagFunc: validateDuration,
+				Description:      "Minimum wait between retries, as a duration such as `500ms`. The wait grows exponentially with jitter up to `retry_wait_max`. A `Retry-After` header from the API takes precedence, up to `retry_wait_max`.",
+			},
+			"retry_wait_max": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "30s",
+				ValidateDiagFunc: validateDuration,
+				Description:      "Maximum wait between retries, as a duration such as `30s`. Also caps the wait requested by a `Retry-After` header.",
+			},
+			"request_timeout": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "0s",
+				ValidateDiagFunc: validateDuration,
+				Description:      "Timeout of a single API request attempt, as a duration such as `60s`. `0s` disables the timeout.",
+			},
+			"retry_non_idempotent": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Also retry non-idempotent (POST) requests. A retried create may be applied twice if the first attempt reached the orchestrator.",
+			},
+			"edge_read_cache": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Read each gateway from the API once per run and share it between the gateway and its sub-resources. Any change to a gateway made by the provider drops the cached copy. Disable to read the gateway for every resource.",
+			},
 		},
-		ResourcesMap: map[string]*schema.Resource{
-			"netskopebwan_tenant":               resourceTenant(),
-			"netskopebwan_user":                 resourceUser(),
-			"netskopebwan_gateway":              resourceGateway(),
-			"netskopebwan_gateway_interface":    resourceGatewayInterface(),
-			"netskopebwan_gateway_bgpconfig":    resourceGatewayBgp(),
-			"netskopebwan_gateway_nat":          resourceGatewayNat(),
-			"netskopebwan_gateway_port_forward": resourceGatewayPortForward(),
-			"netskopebwan_gateway_staticroute":  resourceGatewayStaticRoute(),
-			"netskopebwan_policy":               resourcePolicy(),
-			"netskopebwan_gateway_activate":     resourceGatewayActivate(),
-		},
-		DataSourcesMap: map[string]*schema.Resource{
-			"netskopebwan_tenant":               dataSourceTenant(),
-			"netskopebwan_user":                 dataSourceUser(),
-			"netskopebwan_gateway":              dataSourceGateway(),
-			"netskopebwan_gateway_interface":    dataSourceGatewayInterface(),
-			"netskopebwan_gateway_bgpconfig":    dataSourceGatewayBgp(),
-			"netskopebwan_gateway_nat":          dataSourceGatewayNat(),
-			"netskopebwan_gateway_port_forward": dataSourceGatewayPortForward(),
-			"netskopebwan_gateway_staticroute":  dataSourceGatewayStaticRoute(),
-			"netskopebwan_policy":               dataSourcePolicy(),
+		ResourcesMap:   build("resource", resources),
+		DataSourcesMap: build("data source", dataSources),
+		ConfigureContextFunc: func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+			if diags.HasError() {
+				return nil, diags
+			}
+
+			client, err := providerConfigure(d)
+			if err != nil {
+				return nil, diag.FromErr(err)
+			}
+
+			return client, nil
 		},
-		ConfigureFunc: providerConfigure,
 	}
 }
 
+// brokenResource returns a resource of the given kind with an empty schema,
+// failing every operation with d.
+func brokenResource(kind string, d diag.Diagnostic) *schema.Resource {
+	fail := func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		return diag.Diagnostics{d}
+	}
+
+	r := &schema.Resource{
+		Schema:      map[string]*schema.Schema{},
+		ReadContext: fail,
+	}
+	if kind == "resource" {
+		r.CreateContext = fail
+		r.DeleteContext = fail
+	}
+
+	return r
+}
+
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
-	nsclient := swagger.NewAPIClient(
+	transport := &utils.RetryTransport{
+		MaxRetries:         d.Get("max_retries").(int),
+		RetryNonIdempotent: d.Get("retry_non_idempotent").(bool),
+	}
+
+	for key, v := range map[string]*time.Duration{
+		"retry_wait_min":  &transport.WaitMin,
+		"retry_wait_max":  &transport.WaitMax,
+		"request_timeout": &transport.RequestTimeout,
+	} {
+		duration, err := time.ParseDuration(d.Get(key).(string))
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", key, err)
+		}
+		*v = duration
+	}
+
+	if transport.WaitMax < transport.WaitMin {
+		return nil, fmt.Errorf("retry_wait_max (%v) is less than retry_wait_min (%v)",
+			transport.WaitMax, transport.WaitMin)
+	}
+
+	var rt http.RoundTripper = transport
+	if d.Get("edge_read_cache").(bool) {
+		rt = newEdgeCache(rt)
+	}
+
+	nsclient := newAPIClient(
 		&swagger.Configuration{
 			BasePath: d.Get("baseurl").(string),
 			DefaultHeader: map[string]string{
 				"Authorization": "Bearer " + d.Get("apitoken").(string),
 			},
+			HTTPClient: &http.Client{Transport: rt},
 		},
 	)
 	return nsclient, nil
 }
+
+var validateDuration = validation.ToDiagFunc(ValidateDuration)
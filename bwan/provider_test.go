@@ -0,0 +1,112 @@
+package bwan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider(t *testing.T) {
+	require.NoError(t, Provider().InternalValidate())
+}
+
+func TestProviderMaxRetries(t *testing.T) {
+	p := Provider()
+
+	diags := p.Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"baseurl":     "https://example.com",
+		"apitoken":    "token",
+		"max_retries": -1,
+	}))
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "expected max_retries to be at least (0)")
+
+	diags = p.Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"baseurl":     "https://example.com",
+		"apitoken":    "token",
+		"max_retries": 0,
+	}))
+	assert.False(t, diags.HasError(), "%v", diags)
+}
+
+func TestProviderSchemaError(t *testing.T) {
+	broken := func() (*schema.Resource, error) {
+		_, _, _, err := ReflectSchema(UnsupportedObject{}, Cfg{})
+		return nil, err
+	}
+
+	p := newProvider(map[string]resourceFunc{
+		"netskopebwan_tenant": resourceTenant,
+		"netskopebwan_broken": broken,
+	}, map[string]resourceFunc{
+		"netskopebwan_broken": broken,
+	})
+	require.NoError(t, p.InternalValidate())
+	assert.NotEmpty(t, p.ResourcesMap["netskopebwan_tenant"].Schema)
+
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"baseurl":  "https://example.com",
+		"apitoken": "token",
+	}))
+	require.Len(t, diags, 2)
+	assert.Equal(t, "Invalid schema of resource netskopebwan_broken", diags[0].Summary)
+	assert.Equal(t, "Invalid schema of data source netskopebwan_broken", diags[1].Summary)
+	assert.Equal(t, "children.events: unsupported type chan string", diags[0].Detail)
+
+	diags = p.ResourcesMap["netskopebwan_broken"].ReadContext(context.Background(), nil, nil)
+	assert.Equal(t, "Invalid schema of resource netskopebwan_broken", diags[0].Summary)
+}
+
+func TestProviderOpenAPIError(t *testing.T) {
+	fields := openAPIFields
+	t.Cleanup(func() { openAPIFields = fields })
+	openAPIFields = func() (map[string]map[string]openAPIField, error) {
+		return parseOpenAPIFields([]byte(`{"edge":`))
+	}
+
+	_, err := resourceTenant()
+	var serr *SchemaError
+	require.ErrorAs(t, err, &serr)
+	assert.Contains(t, serr.Reason, "openapi.json: unexpected end of JSON input")
+
+	p := newProvider(map[string]resourceFunc{
+		"netskopebwan_tenant": resourceTenant,
+	}, nil)
+	require.NoError(t, p.InternalValidate())
+
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"baseurl":  "https://example.com",
+		"apitoken": "token",
+	}))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Invalid schema of resource netskopebwan_tenant", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "openapi.json: unexpected end of JSON input")
+}
+
+// mustResource builds the resource or data source of f, failing the test if
+// its schema cannot be reflected.
+func mustResource(t *testing.T, f resourceFunc) *schema.Resource {
+	t.Helper()
+
+	r, err := f()
+	require.NoError(t, err)
+
+	return r
+}
+
+// testAPIClient returns a client talking to a fake orchestrator served by h.
+func testAPIClient(t *testing.T, h http.Handler) *apiClient {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	cfg := swagger.NewConfiguration()
+	cfg.BasePath = srv.URL
+	return newAPIClient(cfg)
+}
@@ -1,6 +1,7 @@
 package bwan
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 var matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
@@ -23,16 +25,71 @@ func ToSnakeCase(field reflect.StructField) string {
 	} else {
 		str = strings.Split(tag, ",")[0]
 	}
+	return toSnakeCase(str)
+}
+
+func toSnakeCase(str string) string {
 	snake := matchFirstCap.ReplaceAllString(str, "${1}_${2}")
 	snake = matchAllCap.ReplaceAllString(snake, "${1}_${2}")
 	return strings.ToLower(snake)
 }
 
-type Cfg map[string]struct {
+// serverFields are the top-level fields owned by the orchestrator. Unless
+// configured otherwise, they are reflected as computed-only and left out of
+// the input binder, so they can neither be set in a configuration nor sent
+// to the API.
+var serverFields = map[string]bool{
+	"id":            true,
+	"date_created":  true,
+	"date_modified": true,
+	"created_by":    true,
+	"modified_by":   true,
+	"activated":     true,
+	"state":         true,
+}
+
+// sensitiveFields matches the names of fields holding secrets, at any
+// depth, e.g. the PSK of an edge, the RADIUS secret of an interface or the
+// activation token of a gateway. They are reflected as sensitive so that
+// they are redacted from plan output. Other fields are marked through the
+// Cfg.
+var sensitiveFields = regexp.MustCompile(`^(psk|password|secret|key|public_key|token)$`)
+
+// Cfg overrides the reflected schema per dotted field path, e.g.
+// "addresses.address" for the address of every element of addresses.
+type Cfg map[string]FieldCfg
+
+// FieldCfg is merged into the reflected schema of a field. The attributes
+// set in Schema are kept, while Type and Elem always follow the Go type,
+// except that a slice may be declared a TypeSet. Validate checks the value
+// of a primitive field, or each element of a list of primitives. SpecEnum
+// validates a string field against the enum of the field in the OpenAPI
+// spec, and fails the reflection if the spec has none.
+type FieldCfg struct {
 	schema.Schema
+	Validate schema.SchemaValidateFunc
+	SpecEnum bool
+}
+
+// SchemaError reports a Go type that cannot be reflected into a schema, a
+// Cfg that does not fit the reflected one, or a value that does not fit the
+// Go type it is bound to.
+type SchemaError struct {
+	// Path is the dotted path of the field, or "" for the root.
+	Path   string
+	Type   reflect.Type
+	Reason string
 }
 
-func ReflectSchema(v interface{}, cfg Cfg) (map[string]*schema.Schema, []FieldBinder, []FieldBinder) {
+func (e *SchemaError) Error() string {
+	if e.Path == "" {
+		return e.Reason
+	}
+
+	return e.Path + ": " + e.Reason
+}
+
+func ReflectSchema(v interface{}, cfg Cfg) (map[string]*schema.Schema, []FieldBinder, []FieldBinder, error) {
 	t := reflect.TypeOf(v)
 	return reflectSchemaType("", t, cfg)
 }
@@ -81,7 +138,11 @@ func applyBinderInput(t reflect.Type, bm []FieldBinder, get func(k string) (inte
 		f := nv.FieldByName(b.FieldName)
 
 		if !f.IsValid() {
-			panic(fmt.Sprintf("field %v does not exist on %v", b.FieldName, nv.Type().String()))
+			return reflect.Value{}, &SchemaError{
+				Path:   b.MapKey,
+				Type:   t,
+				Reason: fmt.Sprintf("field %v does not exist on %v", b.FieldName, t),
+			}
 		}
 
 		mv, ok := get(b.MapKey)
@@ -100,34 +161,69 @@ func applyBinderInput(t reflect.Type, bm []FieldBinder, get func(k string) (inte
 
 		switch f.Type().Kind() {
 		case reflect.Array, reflect.Slice:
+			if v.Kind() != reflect.Array && v.Kind() != reflect.Slice {
+				return reflect.Value{}, convertError(b.MapKey, v, f.Type())
+			}
+
 			nv := reflect.MakeSlice(reflect.SliceOf(f.Type().Elem()), 0, 0)
 
 			for i := 0; i < v.Len(); i++ {
-				nv = reflect.Append(nv, v.Index(i).Convert(f.Type().Elem()))
+				ev, err := convertValue(b.MapKey, v.Index(i), f.Type().Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+
+				nv = reflect.Append(nv, ev)
 			}
 
 			v = nv
 		}
 
-		f.Set(v.Convert(f.Type()))
+		v, err = convertValue(b.MapKey, v, f.Type())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		f.Set(v)
 	}
 
 	return nv, nil
 }
 
-func safeResourceDataSet(d *schema.ResourceData, k string, v interface{}) (rerr error) {
-	defer func() {
-		if r := recover(); r != nil {
-			rerr = fmt.Errorf("panic: %v", r)
-		}
-	}()
+// convertValue converts v to t, returning an error rather than panicking if
+// v does not fit. Integers out of the range of t are an error rather than
+// wrapping around, e.g. an AS number above 2147483647 for an int32.
+func convertValue(path string, v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	if !v.IsValid() || !v.Type().ConvertibleTo(t) {
+		return reflect.Value{}, convertError(path, v, t)
+	}
+	if isIntKind(v.Kind()) && isIntKind(t.Kind()) && reflect.Zero(t).OverflowInt(v.Int()) {
+		return reflect.Value{}, fmt.Errorf("%s: %d is out of range for %v", path, v.Int(), t)
+	}
+
+	return v.Convert(t), nil
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func convertError(path string, v reflect.Value, t reflect.Type) error {
+	from := "nil"
+	if v.IsValid() {
+		from = v.Type().String()
+	}
 
-	return d.Set(k, v)
+	return &SchemaError{Path: path, Type: t, Reason: fmt.Sprintf("cannot use %v as %v", from, t)}
 }
 
 func ApplyBinderResourceData(bm []FieldBinder, d *schema.ResourceData, v interface{}) error {
 	return ApplyBinder(bm, reflect.ValueOf(v), func(key string, v interface{}) error {
-		if err := safeResourceDataSet(d, key, v); err != nil {
+		if err := d.Set(key, v); err != nil {
 			return fmt.Errorf("%v: %w", key, err)
 		}
 
@@ -158,9 +254,9 @@ func ApplyBinder(bm []FieldBinder, v reflect.Value, do func(key string, v interf
 	return nil
 }
 
-func reflectSchemaType(path string, t reflect.Type, cfg Cfg) (map[string]*schema.Schema, []FieldBinder, []FieldBinder) {
-	if t.Kind() == reflect.Ptr {
-		panic("ptr not supported")
+func reflectSchemaType(path string, t reflect.Type, cfg Cfg) (map[string]*schema.Schema, []FieldBinder, []FieldBinder, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, nil, nil, &SchemaError{Path: path, Type: t, Reason: fmt.Sprintf("cannot reflect %v, expected a struct", t)}
 	}
 
 	s := map[string]*schema.Schema{}
@@ -177,7 +273,10 @@ func reflectSchemaType(path string, t reflect.Type, cfg Cfg) (map[string]*schema
 			}
 
 			if t.Kind() == reflect.Struct {
-				es, ebm, eibm := reflectSchemaType(path, t, cfg)
+				es, ebm, eibm, err := reflectSchemaType(path, t, cfg)
+				if err != nil {
+					return nil, nil, nil, err
+				}
 
 				for k, v := range es {
 					s[k] = v
@@ -188,7 +287,11 @@ func reflectSchemaType(path string, t reflect.Type, cfg Cfg) (map[string]*schema
 				continue
 			}
 
-			panic("unhandled anonymous field " + t.Kind().String())
+			return nil, nil, nil, &SchemaError{
+				Path:   path,
+				Type:   field.Type,
+				Reason: fmt.Sprintf("unsupported embedded field %v", field.Type),
+			}
 		}
 
 		name := ToSnakeCase(field)
@@ -200,7 +303,21 @@ func reflectSchemaType(path string, t reflect.Type, cfg Cfg) (map[string]*schema
 			fpath = path + "." + name
 		}
 
-		fs, b, ib := reflectSchemaField(fpath, cfg, field.Type, true, false)
+		fs, b, ib, err := reflectSchemaField(fpath, cfg, field.Type, true, false)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if cfg[fpath].SpecEnum {
+			if err := setSpecEnum(fpath, t, field, fs); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		if fs.Description == "" {
+			fs.Description, err = openAPIDescription(t, field)
+			if err != nil {
+				return nil, nil, nil, &SchemaError{Path: fpath, Type: field.Type, Reason: err.Error()}
+			}
+		}
 		s[name] = fs
 		bm = append(bm, FieldBinder{
 			MapKey:    name,
@@ -217,39 +334,147 @@ func reflectSchemaType(path string, t reflect.Type, cfg Cfg) (map[string]*schema
 		}
 	}
 
-	return s, bm, ibm
+	return s, bm, ibm, nil
 }
 
-func reflectSchemaField(path string, cfg Cfg, t reflect.Type, extra, allowDirectObject bool) (*schema.Schema, BinderFunc, BinderFunc) {
-	fcfg, ok := cfg[path]
+func reflectSchemaField(path string, cfg Cfg, t reflect.Type, extra, allowDirectObject bool) (*schema.Schema, BinderFunc, BinderFunc, error) {
+	fcfg := cfg[path]
+
+	// Elements of a slice share the path of the slice, whose overrides do
+	// not apply to them, except for validators of lists of primitives.
+	var s schema.Schema
+	if extra {
+		s = fcfg.Schema
+	}
 
-	s := fcfg.Schema
 	var b, ib BinderFunc
-	s.Type, s.Elem, b, ib = reflectSchemaFieldType(path, t, cfg, allowDirectObject)
-	if extra && !ok {
-		s.Optional = true
-		s.Computed = true
+	var st schema.ValueType
+	var err error
+	st, s.Elem, b, ib, err = reflectSchemaFieldType(path, t, cfg, allowDirectObject)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if extra && !s.Required && !s.Optional && !s.Computed {
+		if !strings.Contains(path, ".") && serverFields[path] {
+			s.Computed = true
+		} else {
+			s.Optional = true
+			s.Computed = true
+		}
 	}
 
-	if s.Type == schema.TypeSet {
+	if st == schema.TypeSet {
 		s.MaxItems = 1
 	}
 
-	return &s, b, ib
+	if extra && isJSONType(t) {
+		s.ValidateDiagFunc = validation.ToDiagFunc(validation.StringIsJSON)
+		s.DiffSuppressFunc = suppressEquivalentJSON
+	}
+
+	if extra && sensitiveFields.MatchString(path[strings.LastIndex(path, ".")+1:]) {
+		s.Sensitive = true
+	}
+
+	if extra && s.Computed && !s.Optional {
+		setComputedOnly(&s)
+		ib = nil
+	}
+
+	switch {
+	case s.Type == schema.TypeInvalid, s.Type == st:
+		s.Type = st
+	case s.Type == schema.TypeSet && st == schema.TypeList:
+	default:
+		return nil, nil, nil, &SchemaError{
+			Path:   path,
+			Type:   t,
+			Reason: fmt.Sprintf("configured as %v but reflected as %v", s.Type, st),
+		}
+	}
+
+	if fcfg.Validate != nil {
+		switch s.Type {
+		case schema.TypeString, schema.TypeInt, schema.TypeFloat, schema.TypeBool:
+			s.ValidateDiagFunc = validation.ToDiagFunc(fcfg.Validate)
+		case schema.TypeMap:
+			return nil, nil, nil, &SchemaError{Path: path, Type: t, Reason: "validators are not supported on maps"}
+		default:
+			if _, ok := s.Elem.(*schema.Schema); !ok {
+				return nil, nil, nil, &SchemaError{Path: path, Type: t, Reason: "validators are not supported on objects"}
+			}
+		}
+	}
+
+	return &s, b, ib, nil
+}
+
+// setSpecEnum sets the validator of the field of the client type t to the
+// enum of the field in the OpenAPI spec.
+func setSpecEnum(path string, t reflect.Type, field reflect.StructField, s *schema.Schema) error {
+	if s.Type != schema.TypeString {
+		return &SchemaError{Path: path, Type: field.Type, Reason: "spec enums are only supported on strings"}
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		name = field.Name
+	}
+	f, ok, err := openAPIProperty(t.Name(), name)
+	if err != nil {
+		return &SchemaError{Path: path, Type: field.Type, Reason: err.Error()}
+	}
+	if !ok || len(f.Enum) == 0 {
+		return &SchemaError{
+			Path:   path,
+			Type:   field.Type,
+			Reason: fmt.Sprintf("the OpenAPI spec has no enum for %s of %s", name, t.Name()),
+		}
+	}
+	s.ValidateDiagFunc = validation.ToDiagFunc(ValidateOneOf(f.Enum...))
+
+	return nil
+}
+
+// setComputedOnly makes s and any attributes nested in it computed-only,
+// dropping the attributes Terraform only allows on configurable fields.
+func setComputedOnly(s *schema.Schema) {
+	s.Required = false
+	s.Optional = false
+	s.Computed = true
+	s.MaxItems = 0
+	s.MinItems = 0
+	s.ValidateDiagFunc = nil
+	s.DiffSuppressFunc = nil
+
+	if r, ok := s.Elem.(*schema.Resource); ok {
+		for _, es := range r.Schema {
+			setComputedOnly(es)
+		}
+	}
 }
 
 type BinderFunc func(v reflect.Value) (interface{}, error)
 
-func defaultBinder(t reflect.Type) BinderFunc {
+func defaultBinder(path string, t reflect.Type) BinderFunc {
 	return func(v reflect.Value) (interface{}, error) {
-		return v.Convert(t).Interface(), nil
+		cv, err := convertValue(path, v, t)
+		if err != nil {
+			return nil, err
+		}
+
+		return cv.Interface(), nil
 	}
 }
 
-func reflectSchemaFieldType(path string, t reflect.Type, cfg Cfg, allowDirectObject bool) (schema.ValueType, interface{}, BinderFunc, BinderFunc) {
+func reflectSchemaFieldType(path string, t reflect.Type, cfg Cfg, allowDirectObject bool) (schema.ValueType, interface{}, BinderFunc, BinderFunc, error) {
 	switch t.Kind() {
 	case reflect.Ptr:
-		st, elem, b, ib := reflectSchemaFieldType(path, t.Elem(), cfg, allowDirectObject)
+		st, elem, b, ib, err := reflectSchemaFieldType(path, t.Elem(), cfg, allowDirectObject)
+		if err != nil {
+			return 0, nil, nil, nil, err
+		}
+
 		return st, elem, func(v reflect.Value) (interface{}, error) {
 				if v.IsNil() {
 					return nil, nil
@@ -271,33 +496,41 @@ func reflectSchemaFieldType(path string, t reflect.Type, cfg Cfg, allowDirectObj
 					return nil, nil
 				}
 
+				cv, err := convertValue(path, reflect.ValueOf(iv), t.Elem())
+				if err != nil {
+					return nil, err
+				}
+
 				p := reflect.New(t.Elem())
-				p.Elem().Set(reflect.ValueOf(iv).Convert(t.Elem()))
+				p.Elem().Set(cv)
 
 				return p.Interface(), nil
-			}
+			}, nil
 	case reflect.String:
 		return schema.TypeString, nil, func(v reflect.Value) (interface{}, error) {
 			return v.String(), nil
-		}, defaultBinder(t)
+		}, defaultBinder(path, t), nil
 	case reflect.Bool:
 		return schema.TypeBool, nil, func(v reflect.Value) (interface{}, error) {
 			return v.Bool(), nil
-		}, defaultBinder(t)
+		}, defaultBinder(path, t), nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return schema.TypeInt, nil, func(v reflect.Value) (interface{}, error) {
 			return v.Int(), nil
-		}, defaultBinder(t)
+		}, defaultBinder(path, t), nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return schema.TypeInt, nil, func(v reflect.Value) (interface{}, error) {
 			return v.Uint(), nil
-		}, defaultBinder(t)
+		}, defaultBinder(path, t), nil
 	case reflect.Float64, reflect.Float32:
 		return schema.TypeFloat, nil, func(v reflect.Value) (interface{}, error) {
 			return v.Float(), nil
-		}, defaultBinder(t)
+		}, defaultBinder(path, t), nil
 	case reflect.Slice, reflect.Array:
-		s, b, ib := reflectSchemaField(path, cfg, t.Elem(), false, true)
+		s, b, ib, err := reflectSchemaField(path, cfg, t.Elem(), false, true)
+		if err != nil {
+			return 0, nil, nil, nil, err
+		}
 
 		var innerType interface{} = s
 		if s.Type == SchemaStruct {
@@ -325,7 +558,16 @@ func reflectSchemaFieldType(path string, t reflect.Type, cfg Cfg, allowDirectObj
 					return nil, nil
 				}
 
-				av := v.Interface().([]interface{})
+				var av []interface{}
+				switch vi := v.Interface().(type) {
+				case []interface{}:
+					av = vi
+				case *schema.Set:
+					// Lists may be turned into sets after reflection.
+					av = vi.List()
+				default:
+					return nil, convertError(path, v, t)
+				}
 				nv := reflect.MakeSlice(t, 0, len(av))
 
 				for _, iv := range av {
@@ -334,11 +576,68 @@ func reflectSchemaFieldType(path string, t reflect.Type, cfg Cfg, allowDirectObj
 						return nil, err
 					}
 
-					nv = reflect.Append(nv, reflect.ValueOf(niv))
+					ev := reflect.Zero(t.Elem())
+					if niv != nil {
+						ev, err = convertValue(path, reflect.ValueOf(niv), t.Elem())
+						if err != nil {
+							return nil, err
+						}
+					}
+
+					nv = reflect.Append(nv, ev)
 				}
 
 				return nv.Interface(), nil
-			}
+			}, nil
+	case reflect.Map:
+		if isJSONType(t) {
+			break
+		}
+
+		_, _, eb, eib, err := reflectSchemaFieldType(path, t.Elem(), cfg, false)
+		if err != nil {
+			return 0, nil, nil, nil, err
+		}
+
+		return schema.TypeMap, &schema.Schema{Type: mapElemType(t.Elem())}, func(v reflect.Value) (interface{}, error) {
+				if v.IsNil() {
+					return nil, nil
+				}
+
+				m := make(map[string]interface{}, v.Len())
+				for _, k := range v.MapKeys() {
+					ev, err := eb(v.MapIndex(k))
+					if err != nil {
+						return nil, err
+					}
+
+					m[k.String()] = ev
+				}
+
+				return m, nil
+			}, func(v reflect.Value) (interface{}, error) {
+				mv, ok := v.Interface().(map[string]interface{})
+				if !ok {
+					return nil, convertError(path, v, t)
+				}
+
+				nv := reflect.MakeMapWithSize(t, len(mv))
+				for k, ev := range mv {
+					niv, err := eib(reflect.ValueOf(ev))
+					if err != nil {
+						return nil, err
+					}
+
+					ev, err := convertValue(path, reflect.ValueOf(niv), t.Elem())
+					if err != nil {
+						return nil, err
+					}
+
+					nv.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), ev)
+				}
+
+				return nv.Interface(), nil
+			}, nil
 	case reflect.Struct:
 		if t.AssignableTo(reflect.TypeOf(time.Time{})) {
 			return schema.TypeString, nil, func(v reflect.Value) (interface{}, error) {
@@ -346,16 +645,20 @@ func reflectSchemaFieldType(path string, t reflect.Type, cfg Cfg, allowDirectObj
 
 					return t.Format(time.RFC3339), nil
 				}, func(v reflect.Value) (interface{}, error) {
-					sv := v.Interface().(string)
+					sv, ok := v.Interface().(string)
+					if !ok {
+						return nil, convertError(path, v, t)
+					}
 
 					return time.Parse(time.RFC3339, sv)
-				}
+				}, nil
 		}
 
-		if t.PkgPath() == "github.com/infiotinc/netskopebwan-go-client" ||
-			t.PkgPath() == "github.com/netskopeoss/terraform-provider-netskopebwan/bwan" ||
-			t.PkgPath() == "main" {
-			s, bm, ibm := reflectSchemaType(path, t, cfg)
+		if !isJSONType(t) {
+			s, bm, ibm, err := reflectSchemaType(path, t, cfg)
+			if err != nil {
+				return 0, nil, nil, nil, err
+			}
 
 			st := schema.TypeSet
 			if allowDirectObject {
@@ -374,9 +677,10 @@ func reflectSchemaFieldType(path string, t reflect.Type, cfg Cfg, allowDirectObj
 
 					return []interface{}{m}, nil
 				}, func(v reflect.Value) (interface{}, error) {
-					var m map[string]interface{}
+					// An empty block is read as a nil element.
+					var e interface{}
 					if allowDirectObject {
-						m = v.Interface().(map[string]interface{})
+						e = v.Interface()
 					} else {
 						var l []interface{}
 						switch vi := v.Interface().(type) {
@@ -385,14 +689,19 @@ func reflectSchemaFieldType(path string, t reflect.Type, cfg Cfg, allowDirectObj
 						case *schema.Set:
 							l = vi.List()
 						default:
-							panic(fmt.Sprintf("unsupported type %T", vi))
+							return nil, convertError(path, v, t)
 						}
 
 						if len(l) < 1 {
 							return nil, nil
 						}
 
-						m = l[0].(map[string]interface{})
+						e = l[0]
+					}
+
+					m, ok := e.(map[string]interface{})
+					if !ok && e != nil {
+						return nil, convertError(path, reflect.ValueOf(e), t)
 					}
 
 					vo, err := applyBinderInput(t, ibm, func(k string) (interface{}, bool) {
@@ -404,9 +713,96 @@ func reflectSchemaFieldType(path string, t reflect.Type, cfg Cfg, allowDirectObj
 					}
 
 					return vo.Interface(), nil
-				}
+				}, nil
 		}
 	}
 
-	panic(fmt.Sprintf("unahandled type %v: %v", t.PkgPath(), t.String()))
+	if isJSONType(t) {
+		return schema.TypeString, nil, jsonBinder, func(v reflect.Value) (interface{}, error) {
+			sv, _ := v.Interface().(string)
+			if sv == "" {
+				return nil, nil
+			}
+
+			nv := reflect.New(t)
+			if err := json.Unmarshal([]byte(sv), nv.Interface()); err != nil {
+				return nil, fmt.Errorf("invalid JSON: %w", err)
+			}
+
+			return nv.Elem().Interface(), nil
+		}, nil
+	}
+
+	return 0, nil, nil, nil, &SchemaError{Path: path, Type: t, Reason: fmt.Sprintf("unsupported type %v", t)}
+}
+
+// reflectedPackages are the packages whose structs are reflected into
+// nested schemas.
+var reflectedPackages = map[string]bool{
+	"github.com/infiotinc/netskopebwan-go-client":                 true,
+	"github.com/netskopeoss/terraform-provider-netskopebwan/bwan": true,
+	"main": true,
+}
+
+// isJSONType reports whether values of t are reflected as JSON strings:
+// interfaces, maps other than those of strings to primitives, and structs
+// of other packages.
+func isJSONType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Map:
+		return t.Key().Kind() != reflect.String || mapElemType(t.Elem()) == schema.TypeInvalid
+	case reflect.Struct:
+		return !t.AssignableTo(reflect.TypeOf(time.Time{})) && !reflectedPackages[t.PkgPath()]
+	}
+
+	return false
+}
+
+// mapElemType returns the schema type of the values of a TypeMap, or
+// TypeInvalid if values of t cannot be held by one.
+func mapElemType(t reflect.Type) schema.ValueType {
+	switch t.Kind() {
+	case reflect.String:
+		return schema.TypeString
+	case reflect.Bool:
+		return schema.TypeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schema.TypeInt
+	case reflect.Float32, reflect.Float64:
+		return schema.TypeFloat
+	}
+
+	return schema.TypeInvalid
+}
+
+// jsonBinder returns the JSON encoding of v, with the keys of maps sorted.
+func jsonBinder(v reflect.Value) (interface{}, error) {
+	if (v.Kind() == reflect.Interface || v.Kind() == reflect.Map) && v.IsNil() {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	return string(raw), nil
+}
+
+// suppressEquivalentJSON suppresses the diff of JSON strings that differ in
+// formatting only.
+func suppressEquivalentJSON(k, old, new string, d *schema.ResourceData) bool {
+	var ov, nv interface{}
+	if json.Unmarshal([]byte(old), &ov) != nil || json.Unmarshal([]byte(new), &nv) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(ov, nv)
 }
@@ -2,9 +2,13 @@ package bwan
 
 import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	swagger "github.com/infiotinc/netskopebwan-go-client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"net/netip"
 	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -115,13 +119,41 @@ var EmbedObjectSchema = ms{
 		Optional: true,
 		Computed: true,
 	},
+	// Embedded fields are top-level fields, so the ID is the server's.
 	"id": {
 		Type:     schema.TypeString,
+		Computed: true,
+	},
+}
+
+type MapObject struct {
+	Labels map[string]string
+	Counts map[string]int32
+}
+
+var MapObjectSchema = ms{
+	"labels": {
+		Type:     schema.TypeMap,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+		Optional: true,
+		Computed: true,
+	},
+	"counts": {
+		Type:     schema.TypeMap,
+		Elem:     &schema.Schema{Type: schema.TypeInt},
 		Optional: true,
 		Computed: true,
 	},
 }
 
+type JSONObject struct {
+	Any     interface{}
+	Options map[string]interface{}
+	Groups  map[string][]string
+	Prefix  netip.Prefix
+	Blobs   []interface{}
+}
+
 type i = interface{}
 type m = map[string]i
 type ms = map[string]*schema.Schema
@@ -138,10 +170,12 @@ func TestSchema(t *testing.T) {
 		{"array object", ArrayObject{}, ArrayObjectSchema},
 		{"pointer object", PointerObject{}, PointerObjectSchema},
 		{"embed object", EmbedObject{}, EmbedObjectSchema},
+		{"map", MapObject{}, MapObjectSchema},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			sch, _, _ := ReflectSchema(test.t, Cfg{})
+			sch, _, _, err := ReflectSchema(test.t, Cfg{})
+			require.NoError(t, err)
 
 			assert.Equal(t, test.s, sch)
 		})
@@ -277,14 +311,46 @@ func TestRoundTrip(t *testing.T) {
 			"parent_id": "",
 			"id":        "",
 		}},
-		{"embed object", EmbedObject{NestedObjectChild: NestedObjectChild{Id: "id"}}, m{
-			"parent_id": "",
-			"id":        "id",
+		{"embed object", EmbedObject{ParentId: "parent"}, m{
+			"parent_id": "parent",
+			"id":        "",
+		}},
+		{"map", MapObject{}, m{
+			"labels": nil,
+			"counts": nil,
+		}},
+		{"map", MapObject{
+			Labels: map[string]string{},
+			Counts: map[string]int32{"a": 1, "b": -2},
+		}, m{
+			"labels": m{},
+			"counts": m{"a": int64(1), "b": int64(-2)},
+		}},
+		{"json", JSONObject{}, m{
+			"any":     nil,
+			"options": nil,
+			"groups":  nil,
+			"prefix":  `""`,
+			"blobs":   nil,
+		}},
+		{"json", JSONObject{
+			Any:     m{"b": []i{true, nil}, "a": "x"},
+			Options: m{"lease": float64(3600)},
+			Groups:  map[string][]string{"lan": {"GE2", "GE3"}},
+			Prefix:  netip.MustParsePrefix("10.0.0.0/8"),
+			Blobs:   []i{"x", float64(1), m{}},
+		}, m{
+			"any":     `{"a":"x","b":[true,null]}`,
+			"options": `{"lease":3600}`,
+			"groups":  `{"lan":["GE2","GE3"]}`,
+			"prefix":  `"10.0.0.0/8"`,
+			"blobs":   []i{`"x"`, `1`, `{}`},
 		}},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			_, bm, ibm := ReflectSchema(test.t, Cfg{})
+			_, bm, ibm, err := ReflectSchema(test.t, Cfg{})
+			require.NoError(t, err)
 
 			ma, err := ApplyBinderMap(bm, reflect.ValueOf(test.t))
 			require.NoError(t, err)
@@ -301,3 +367,412 @@ func TestRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+type ServerObject struct {
+	Id        string
+	Name      string
+	CreatedBy *NestedObjectChild
+	Children  []NestedObjectChild
+}
+
+func TestSchemaServerFields(t *testing.T) {
+	sch, bm, ibm, err := ReflectSchema(ServerObject{}, Cfg{})
+	require.NoError(t, err)
+
+	assert.Equal(t, &schema.Schema{Type: schema.TypeString, Computed: true}, sch["id"])
+	assert.Equal(t, &schema.Schema{Type: schema.TypeString, Optional: true, Computed: true}, sch["name"])
+
+	// Objects owned by the server are computed-only all the way down.
+	assert.Equal(t, &schema.Schema{
+		Type:     schema.TypeSet,
+		Elem:     &schema.Resource{Schema: ms{"id": {Type: schema.TypeString, Computed: true}}},
+		Computed: true,
+	}, sch["created_by"])
+
+	// Only top-level fields are the server's, nested IDs are references.
+	assert.Equal(t, NestedObjectChildSchema, sch["children"].Elem.(*schema.Resource).Schema)
+
+	r := &schema.Resource{Schema: sch}
+	require.NoError(t, r.InternalValidate(nil, true))
+
+	// Server fields are read, but never sent.
+	var keys []string
+	for _, b := range bm {
+		keys = append(keys, b.MapKey)
+	}
+	assert.Equal(t, []string{"id", "name", "created_by", "children"}, keys)
+
+	keys = nil
+	for _, b := range ibm {
+		keys = append(keys, b.MapKey)
+	}
+	assert.Equal(t, []string{"name", "children"}, keys)
+
+	in, err := ApplyBinderInput[ServerObject](ibm, func(k string) (interface{}, bool) {
+		v, ok := m{"id": "id", "name": "name"}[k]
+		return v, ok
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ServerObject{Name: "name"}, in)
+
+	// The configuration takes precedence, e.g. for data sources looking
+	// objects up by ID.
+	sch, _, ibm, err = ReflectSchema(ServerObject{}, Cfg{
+		"id": {Schema: schema.Schema{Optional: true, Computed: true}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &schema.Schema{Type: schema.TypeString, Optional: true, Computed: true}, sch["id"])
+	assert.Equal(t, "id", ibm[0].MapKey)
+}
+
+type SecretObject struct {
+	Name   string
+	Psk    string
+	Peer   SecretObjectPeer
+	Peers  []SecretObjectPeer
+	Tokens []string
+}
+
+type SecretObjectPeer struct {
+	PublicKey string
+	KeyId     string
+}
+
+func TestSchemaSensitive(t *testing.T) {
+	sch, _, _, err := ReflectSchema(SecretObject{}, Cfg{
+		"tokens": {Schema: schema.Schema{Optional: true, Sensitive: true}},
+	})
+	require.NoError(t, err)
+
+	assert.False(t, sch["name"].Sensitive)
+	assert.True(t, sch["psk"].Sensitive)
+	assert.True(t, sch["tokens"].Sensitive)
+	assert.False(t, sch["tokens"].Elem.(*schema.Schema).Sensitive)
+
+	for _, k := range []string{"peer", "peers"} {
+		peer := sch[k].Elem.(*schema.Resource).Schema
+		assert.False(t, sch[k].Sensitive, k)
+		assert.True(t, peer["public_key"].Sensitive, k)
+		assert.False(t, peer["key_id"].Sensitive, k)
+	}
+}
+
+// sensitivePaths returns the sorted dotted paths of the attributes of sch
+// that are marked sensitive.
+func sensitivePaths(prefix string, sch map[string]*schema.Schema) []string {
+	paths := []string{}
+	for k, s := range sch {
+		if s.Sensitive {
+			paths = append(paths, prefix+k)
+		}
+		if r, ok := s.Elem.(*schema.Resource); ok {
+			paths = append(paths, sensitivePaths(prefix+k+".", r.Schema)...)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestSchemaSensitiveCoverage(t *testing.T) {
+	p := Provider()
+
+	edgeSecrets := []string{
+		"interfaces.lte_props.password",
+		"interfaces.radius.secret",
+		"interfaces.wifi_props.encryption.key",
+		"psk",
+		"public_key",
+	}
+	interfaceSecrets := []string{
+		"lte_props.password",
+		"radius.secret",
+		"wifi_props.encryption.key",
+	}
+	gatewaysSecrets := []string{}
+	for _, path := range edgeSecrets {
+		gatewaysSecrets = append(gatewaysSecrets, "gateways."+path)
+	}
+
+	// Any resource or data source not listed has no sensitive attributes.
+	expected := map[string]map[string][]string{
+		"resource": {
+			"netskopebwan_gateway":           edgeSecrets,
+			"netskopebwan_gateway_activate":  {"token"},
+			"netskopebwan_gateway_interface": interfaceSecrets,
+		},
+		"data source": {
+			"netskopebwan_gateway":           edgeSecrets,
+			"netskopebwan_gateway_bootstrap": {"cloud_init", "env", "rendered", "token"},
+			"netskopebwan_gateway_interface": interfaceSecrets,
+			"netskopebwan_gateways":          gatewaysSecrets,
+		},
+	}
+	all := map[string]map[string]*schema.Resource{
+		"resource":    p.ResourcesMap,
+		"data source": p.DataSourcesMap,
+	}
+	for kind, resources := range all {
+		for name, r := range resources {
+			want := expected[kind][name]
+			if want == nil {
+				want = []string{}
+			}
+			assert.Equal(t, want, sensitivePaths("", r.Schema), "%s %s", kind, name)
+		}
+	}
+	assert.Equal(t, []string{"apitoken"}, sensitivePaths("", p.Schema), "provider")
+}
+
+func TestSchemaDescriptions(t *testing.T) {
+	sch, _, _, err := ReflectSchema(swagger.Edge{}, Cfg{
+		"name": {Schema: schema.Schema{Required: true, Description: "The name."}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "The name.", sch["name"].Description)
+	assert.Equal(t, "Serial number of the edge.", sch["serialnumber"].Description)
+	assert.Equal(t, "Edge's role. One of `hub`, `spoke` or `dcedge`.", sch["role"].Description)
+	assert.Empty(t, sch["psk"].Description)
+
+	// Fields of composed schemas, e.g. ObjectProps.
+	assert.Contains(t, sch["date_created"].Description, "ISO 8601")
+
+	// Nested schemas are matched by type, including names the client
+	// spells differently, e.g. EdgeBGPConfiguration.
+	bgp := sch["bgp_configuration"].Elem.(*schema.Resource).Schema
+	assert.Equal(t, "BFD interval in milli seconds.", bgp["bfd_interval"].Description)
+	iface := sch["interfaces"].Elem.(*schema.Resource).Schema
+	assert.Equal(t, "One of `routed`, `access` or `trunk`. The orchestrator defaults to `routed`.",
+		iface["mode"].Description)
+	assert.Equal(t, "Advertise the interface subnet. The orchestrator defaults to `false`.",
+		iface["do_advertise"].Description)
+	addresses := iface["addresses"].Elem.(*schema.Resource).Schema
+	assert.NotEmpty(t, addresses["address_assignment"].Description)
+
+	// Types of the provider are not in the spec.
+	sch, _, _, err = ReflectSchema(resourceGatewayStaticRouteInput{}, Cfg{})
+	require.NoError(t, err)
+	assert.Empty(t, sch["gateway_id"].Description)
+}
+
+func TestSchemaJSON(t *testing.T) {
+	sch, bm, ibm, err := ReflectSchema(JSONObject{}, Cfg{})
+	require.NoError(t, err)
+
+	for _, k := range []string{"any", "options", "groups", "prefix"} {
+		assert.Equal(t, schema.TypeString, sch[k].Type, k)
+		assert.NotNil(t, sch[k].ValidateDiagFunc, k)
+		assert.NotNil(t, sch[k].DiffSuppressFunc, k)
+	}
+	assert.Equal(t, schema.TypeList, sch["blobs"].Type)
+	assert.Equal(t, &schema.Schema{Type: schema.TypeString}, sch["blobs"].Elem)
+
+	r := &schema.Resource{Schema: sch}
+	require.NoError(t, r.InternalValidate(nil, true))
+
+	diags := r.Validate(terraform.NewResourceConfigRaw(m{"any": "{"}))
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Summary, `"any" contains an invalid JSON`)
+
+	// Values round-trip through the resource data in their canonical
+	// encoding, and differently formatted JSON is not a change.
+	d := schema.TestResourceDataRaw(t, sch, m{
+		"any":    `{ "b": 2, "a": [1] }`,
+		"groups": `{"lan": ["GE2"]}`,
+		"blobs":  []i{`"x"`},
+	})
+	in, err := ApplyBinderInputResourceData[JSONObject](ibm, d)
+	require.NoError(t, err)
+	assert.Equal(t, JSONObject{
+		Any:    m{"a": []i{float64(1)}, "b": float64(2)},
+		Groups: map[string][]string{"lan": {"GE2"}},
+		Blobs:  []i{"x"},
+	}, in)
+
+	require.NoError(t, ApplyBinderResourceData(bm, d, in))
+	assert.Equal(t, `{"a":[1],"b":2}`, d.Get("any"))
+	assert.True(t, suppressEquivalentJSON("any", `{"a":[1],"b":2}`, `{ "b": 2, "a": [1] }`, d))
+	assert.False(t, suppressEquivalentJSON("any", `{"a":[1],"b":2}`, `{"a":[1],"b":3}`, d))
+
+	_, err = ApplyBinderInput[JSONObject](ibm, func(k string) (interface{}, bool) {
+		return `{"lan": "GE2"}`, k == "groups"
+	})
+	assert.ErrorContains(t, err, "invalid JSON")
+}
+
+func TestSchemaMapResourceData(t *testing.T) {
+	sch, bm, ibm, err := ReflectSchema(MapObject{}, Cfg{})
+	require.NoError(t, err)
+
+	r := &schema.Resource{Schema: sch}
+	require.NoError(t, r.InternalValidate(nil, true))
+
+	d := schema.TestResourceDataRaw(t, sch, m{
+		"labels": m{"env": "prod"},
+		"counts": m{"a": 1},
+	})
+	in, err := ApplyBinderInputResourceData[MapObject](ibm, d)
+	require.NoError(t, err)
+	assert.Equal(t, MapObject{
+		Labels: map[string]string{"env": "prod"},
+		Counts: map[string]int32{"a": 1},
+	}, in)
+
+	in.Counts["b"] = 2
+	require.NoError(t, ApplyBinderResourceData(bm, d, in))
+	assert.Equal(t, m{"a": 1, "b": 2}, d.Get("counts"))
+}
+
+type ValidatedObject struct {
+	Name     string
+	Port     int
+	Strings  []string
+	Children []NestedObjectChild
+}
+
+func TestSchemaValidators(t *testing.T) {
+	sch, _, _, err := ReflectSchema(ValidatedObject{}, Cfg{
+		"name":        {Schema: schema.Schema{Required: true}, Validate: ValidateOneOf("a", "b")},
+		"port":        {Validate: ValidatePort},
+		"strings":     {Schema: schema.Schema{Type: schema.TypeSet, Optional: true}, Validate: ValidateIPv4},
+		"children.id": {Validate: ValidateIPv4CIDR},
+	})
+	require.NoError(t, err)
+
+	// Validators leave the reflected type and the default mode alone.
+	assert.Equal(t, schema.TypeString, sch["name"].Type)
+	assert.True(t, sch["name"].Required)
+	assert.NotNil(t, sch["name"].ValidateDiagFunc)
+	assert.Equal(t, schema.TypeInt, sch["port"].Type)
+	assert.True(t, sch["port"].Optional)
+	assert.True(t, sch["port"].Computed)
+	assert.NotNil(t, sch["port"].ValidateDiagFunc)
+
+	// Lists of primitives validate their elements.
+	assert.Equal(t, schema.TypeSet, sch["strings"].Type)
+	assert.Zero(t, sch["strings"].MaxItems)
+	assert.Nil(t, sch["strings"].ValidateDiagFunc)
+	assert.NotNil(t, sch["strings"].Elem.(*schema.Schema).ValidateDiagFunc)
+	assert.False(t, sch["strings"].Elem.(*schema.Schema).Optional)
+
+	child := sch["children"].Elem.(*schema.Resource).Schema["id"]
+	assert.NotNil(t, child.ValidateDiagFunc)
+
+	r := &schema.Resource{Schema: sch}
+	require.NoError(t, r.InternalValidate(nil, true))
+
+	diags := r.Validate(terraform.NewResourceConfigRaw(m{
+		"name":     "c",
+		"port":     70000,
+		"strings":  []i{"10.0.0.1", "10.0.0"},
+		"children": []i{m{"id": "10.0.0.0/8"}, m{"id": "fe80::/10"}},
+	}))
+	var summaries []string
+	for _, d := range diags {
+		summaries = append(summaries, d.Summary)
+	}
+	assert.ElementsMatch(t, []string{
+		`expected name to be one of ["a" "b"], got c`,
+		`expected "port" to be a valid port number, got: 70000`,
+		`expected strings to contain a valid IPv4 address, got: 10.0.0`,
+		`expected id to be an IPv4 CIDR such as "10.0.0.0/8", got "fe80::/10"`,
+	}, summaries)
+
+	diags = r.Validate(terraform.NewResourceConfigRaw(m{
+		"name":     "a",
+		"port":     443,
+		"strings":  []i{"10.0.0.1"},
+		"children": []i{m{"id": "10.0.0.1/24"}},
+	}))
+	assert.Empty(t, diags)
+}
+
+func TestSchemaSpecEnum(t *testing.T) {
+	sch, _, _, err := ReflectSchema(swagger.InterfaceSettingsAddresses{}, Cfg{
+		"address_assignment": {SpecEnum: true},
+	})
+	require.NoError(t, err)
+
+	r := &schema.Resource{Schema: sch}
+	for _, v := range []string{"static", "dhcp"} {
+		assert.Empty(t, r.Validate(terraform.NewResourceConfigRaw(m{"address_assignment": v})), v)
+	}
+	diags := r.Validate(terraform.NewResourceConfigRaw(m{"address_assignment": "pppoe"}))
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Summary, "expected address_assignment to be one of")
+}
+
+func TestSchemaConfigErrors(t *testing.T) {
+	for _, test := range []struct {
+		v     interface{}
+		cfg   Cfg
+		path  string
+		error string
+	}{
+		{ValidatedObject{}, Cfg{"name": {Schema: schema.Schema{Type: schema.TypeInt}}},
+			"name", "name: configured as TypeInt but reflected as TypeString"},
+		{ValidatedObject{}, Cfg{"children": {Validate: ValidateIPv4}},
+			"children", "children: validators are not supported on objects"},
+		{MapObject{}, Cfg{"labels": {Validate: ValidateIPv4}},
+			"labels", "labels: validators are not supported on maps"},
+		{&ValidatedObject{}, Cfg{},
+			"", "cannot reflect *bwan.ValidatedObject, expected a struct"},
+		{UnsupportedObject{}, Cfg{},
+			"children.events", "children.events: unsupported type chan string"},
+		{swagger.InterfaceSettingsAddresses{}, Cfg{"address": {SpecEnum: true}},
+			"address", "address: the OpenAPI spec has no enum for address of InterfaceSettingsAddresses"},
+		{ValidatedObject{}, Cfg{"name": {SpecEnum: true}},
+			"name", "name: the OpenAPI spec has no enum for Name of ValidatedObject"},
+		{ValidatedObject{}, Cfg{"port": {SpecEnum: true}},
+			"port", "port: spec enums are only supported on strings"},
+	} {
+		_, _, _, err := ReflectSchema(test.v, test.cfg)
+
+		var serr *SchemaError
+		require.ErrorAs(t, err, &serr, test.error)
+		assert.Equal(t, test.path, serr.Path)
+		assert.EqualError(t, err, test.error)
+	}
+}
+
+type UnsupportedObject struct {
+	Children []UnsupportedObjectChild
+}
+
+type UnsupportedObjectChild struct {
+	Events chan string
+}
+
+func TestBinderInputErrors(t *testing.T) {
+	_, _, ibm, err := ReflectSchema(NestedObject{}, Cfg{})
+	require.NoError(t, err)
+
+	// A value of the wrong shape, e.g. from a schema changed by hand.
+	_, err = ApplyBinderInput[NestedObject](ibm, func(k string) (interface{}, bool) {
+		if k == "child" {
+			return "child", true
+		}
+		return nil, false
+	})
+	var serr *SchemaError
+	require.ErrorAs(t, err, &serr)
+	assert.Equal(t, "child", serr.Path)
+
+	// A binder of another type.
+	_, err = ApplyBinderInput[ServerObject](ibm, func(k string) (interface{}, bool) {
+		return nil, false
+	})
+	require.ErrorAs(t, err, &serr)
+	assert.EqualError(t, err, "child: field Child does not exist on bwan.ServerObject")
+
+	// A 4 byte AS number does not fit the int32 of the client.
+	_, _, ibm, err = ReflectSchema(swagger.EdgeBgpConfiguration{}, Cfg{})
+	require.NoError(t, err)
+	_, err = ApplyBinderInput[swagger.EdgeBgpConfiguration](ibm, func(k string) (interface{}, bool) {
+		if k == "remote_as" {
+			return int64(4200000000), true
+		}
+		return nil, false
+	})
+	assert.EqualError(t, err, "remote_as: 4200000000 is out of range for int32")
+}
@@ -2,7 +2,6 @@ package bwan
 
 import (
 	"context"
-	"fmt"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
@@ -15,7 +14,7 @@ func (rt _resourceGateway) resourceGatewayCreate(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 	gwInput, err := ApplyBinderInputResourceData[swagger.Edge](rt.InputBinder, d)
 	if err != nil {
 		return diag.FromErr(err)
@@ -30,12 +29,9 @@ func (rt _resourceGateway) resourceGatewayCreate(
 		Serialnumber:   gwInput.Serialnumber,
 	}
 
-	gateway, _, err := apiSvc.EdgesApi.AddEdge(ctx, addGwInput, nil)
+	gateway, resp, err := apiSvc.EdgesApi.AddEdge(ctx, addGwInput, nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return apiError("AddEdge", resp, err, rt.Binder)
 	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, gateway)
@@ -53,17 +49,14 @@ func (rt _resourceGateway) resourceGatewayRead(
 	var diags diag.Diagnostics
 	var err error
 
-	apiSvc := m.(*swagger.APIClient)
-	gwInput, err := ApplyBinderInputResourceData[swagger.Edge](rt.InputBinder, d)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, gwInput.Id, nil)
+	apiSvc := m.(*apiClient)
+	gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, d.Id(), nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
+		if apiSvc.isNotFound(err, resp, "/edges/"+d.Id()) {
+			d.SetId("")
+			return diags
 		}
-		return diag.FromErr(err)
+		return apiError("GetEdgeById", resp, err, rt.Binder)
 	}
 	err = ApplyBinderResourceData(rt.Binder, d, gateway)
 	if err != nil {
@@ -78,11 +71,11 @@ func (rt _resourceGateway) resourceGatewayUpdate(
 	var diags diag.Diagnostics
 	var err error
 
-	if d.Get("id").(string) == "" {
+	if d.Id() == "" {
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 	gwInput, err := ApplyBinderInputResourceData[swagger.Edge](rt.InputBinder, d)
 	if err != nil {
 		return diag.FromErr(err)
@@ -91,11 +84,8 @@ func (rt _resourceGateway) resourceGatewayUpdate(
 	addGwInput := swagger.UpdateEdgeInput{
 		Name:                   gwInput.Name,
 		Role:                   gwInput.Role,
-		AssignedPolicy:         gwInput.AssignedPolicy,
 		Description:            gwInput.Description,
 		Serialnumber:           gwInput.Serialnumber,
-		Swversion:              gwInput.Swversion,
-		Swmanifest:             gwInput.Swmanifest,
 		Psk:                    gwInput.Psk,
 		BgpConfiguration:       gwInput.BgpConfiguration,
 		StaticRoutes:           gwInput.StaticRoutes,
@@ -103,17 +93,28 @@ func (rt _resourceGateway) resourceGatewayUpdate(
 		PortForwardingNatRules: gwInput.PortForwardingNatRules,
 		Interfaces:             &gwInput.Interfaces,
 	}
+	// The policy and the software are only pushed when changed here, so that
+	// an update does not undo a change made elsewhere, e.g. by
+	// netskopebwan_gateway_software or netskopebwan_gateway_rollout. Once
+	// refreshed, such a change differs from a configured value, which is why
+	// the docs ask to leave them unset or in ignore_changes in that case.
+	if d.HasChange("assigned_policy") {
+		addGwInput.AssignedPolicy = gwInput.AssignedPolicy
+	}
+	if d.HasChange("swversion") {
+		addGwInput.Swversion = gwInput.Swversion
+	}
+	if d.HasChange("swmanifest") {
+		addGwInput.Swmanifest = gwInput.Swmanifest
+	}
 
-	lock := utils.Mutex.Get(gwInput.Id)
+	lock := utils.Mutex.Get(d.Id())
 	lock.Lock()
 	defer lock.Unlock()
-	gateway, _, err := apiSvc.EdgesApi.UpdateEdgeById(ctx, addGwInput, gwInput.Id, nil)
+	gateway, resp, err := apiSvc.EdgesApi.UpdateEdgeById(ctx, addGwInput, d.Id(), nil)
 
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return apiError("UpdateEdgeById", resp, err, rt.Binder)
 	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, gateway)
@@ -130,15 +131,11 @@ func (rt _resourceGateway) resourceGatewayDelete(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	apiSvc := m.(*swagger.APIClient)
-	gwInput, err := ApplyBinderInputResourceData[swagger.Edge](rt.InputBinder, d)
-	if err != nil {
-		return diag.FromErr(err)
-	}
+	apiSvc := m.(*apiClient)
 
-	_, _, err = apiSvc.EdgesApi.DeleteEdgeById(ctx, gwInput.Id, nil)
+	_, resp, err := apiSvc.EdgesApi.DeleteEdgeById(ctx, d.Id(), nil)
 	if err != nil {
-		return diag.FromErr(err)
+		return apiError("DeleteEdgeById", resp, err, rt.Binder)
 	}
 	d.SetId("")
 	return diags
@@ -149,10 +146,13 @@ type _resourceGateway struct {
 	InputBinder []FieldBinder
 }
 
-func resourceGateway() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(swagger.Edge{}, Cfg{
+func resourceGateway() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(swagger.Edge{}, Cfg{
 		"name": {Schema: schema.Schema{Required: true}},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _resourceGateway{Binder: binder, InputBinder: inputBinder}
 
@@ -161,6 +161,9 @@ func resourceGateway() *schema.Resource {
 		ReadContext:   rt.resourceGatewayRead,
 		UpdateContext: rt.resourceGatewayUpdate,
 		DeleteContext: rt.resourceGatewayDelete,
-		Schema:        swaggerSchema,
-	}
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: swaggerSchema,
+	}, nil
 }
@@ -2,7 +2,7 @@ package bwan
 
 import (
 	"context"
-	"fmt"
+	"time"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
@@ -15,28 +15,41 @@ func (rt _resourceGatewayActivate) resourceGatewayActivateCreate(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 	gwActivationInput, err := ApplyBinderInputResourceData[dataSourceGatewayActivationInput](
 		rt.InputBinder, d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	// Only a status published after the activation shows that the device
+	// came up with it.
+	var since time.Time
+	if gwActivationInput.WaitFor == "online" {
+		status, err := lastStatus(ctx, apiSvc, gwActivationInput.GatewayId)
+		if err != nil {
+			return edgeWriteError(err, rt.Binder)
+		}
+		since = status.TimePublished
+	}
+
 	apiInput := swagger.EdgeGenerateActivationCodeInput{
 		EmailAddresses:   gwActivationInput.EmailAddresses,
 		TimeoutInSeconds: gwActivationInput.TimeoutInSeconds,
 	}
 
-	gwActivationData, _, err := apiSvc.EdgesApi.ActivateEdgeById(
+	gwActivationData, resp, err := apiSvc.EdgesApi.ActivateEdgeById(
 		ctx, apiInput, gwActivationInput.GatewayId, nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return apiError("ActivateEdgeById", resp, err, rt.Binder)
 	}
 
 	gwActivationInput.Token = gwActivationData.Token
+	if gwActivationInput.TimeoutInSeconds > 0 {
+		expiresAt := time.Now().UTC().Truncate(time.Second).Add(
+			time.Duration(gwActivationInput.TimeoutInSeconds) * time.Second)
+		gwActivationInput.ExpiresAt = &expiresAt
+	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, gwActivationInput)
 
@@ -45,6 +58,23 @@ func (rt _resourceGatewayActivate) resourceGatewayActivateCreate(
 	}
 
 	d.SetId(utils.Hash(gwActivationData))
+
+	if gwActivationInput.WaitFor != "" {
+		err = waitActivated(ctx, apiSvc, gwActivationInput.GatewayId)
+		if err != nil {
+			return edgeWriteError(err, rt.Binder)
+		}
+		if err = d.Set("activated", true); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if gwActivationInput.WaitFor == "online" {
+		err = waitOnline(ctx, apiSvc, gwActivationInput.GatewayId, since)
+		if err != nil {
+			return edgeWriteError(err, rt.Binder)
+		}
+	}
+
 	return diags
 
 }
@@ -52,9 +82,43 @@ func (rt _resourceGatewayActivate) resourceGatewayActivateCreate(
 func (rt _resourceGatewayActivate) resourceGatewayActivateRead(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
+
+	apiSvc := m.(*apiClient)
+
+	gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, d.Get("gateway_id").(string), nil)
+	if err != nil {
+		if apiSvc.isNotFound(err, resp, "/edges/"+d.Get("gateway_id").(string)) {
+			d.SetId("")
+			return diags
+		}
+		return apiError("GetEdgeById", resp, err, rt.Binder)
+	}
+
+	if err = d.Set("activated", gateway.Activated); err != nil {
+		return diag.FromErr(err)
+	}
 	return diags
 }
 
+// planExpiredToken plans a new activation code once the token has expired
+// while the gateway is still waiting for activation. Once the gateway is
+// activated, its token is of no further use and left alone.
+func planExpiredToken(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || d.Get("activated").(bool) {
+		return nil
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, d.Get("expires_at").(string))
+	if err != nil || time.Now().Before(expiresAt) {
+		return nil
+	}
+
+	if err := d.SetNewComputed("token"); err != nil {
+		return err
+	}
+	return d.ForceNew("token")
+}
+
 func (rt _resourceGatewayActivate) resourceGatewayActivateUpdate(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
@@ -73,15 +137,45 @@ type _resourceGatewayActivate struct {
 }
 
 type dataSourceGatewayActivationInput struct {
-	GatewayId string `json:"gateway_id,omitempty"`
+	GatewayId string     `json:"gateway_id,omitempty"`
+	WaitFor   string     `json:"wait_for,omitempty"`
+	Activated bool       `json:"activated,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 	swagger.EdgeGenerateActivationCodeInput
 	swagger.EdgeActivationCode
 }
 
-func resourceGatewayActivate() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(dataSourceGatewayActivationInput{}, Cfg{
-		"gateway_id": {Schema: schema.Schema{Required: true}},
+func resourceGatewayActivate() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(dataSourceGatewayActivationInput{}, Cfg{
+		"gateway_id":         {Schema: schema.Schema{Required: true, ForceNew: true}},
+		"email_addresses":    {Schema: schema.Schema{Optional: true, ForceNew: true}},
+		"timeout_in_seconds": {Schema: schema.Schema{Optional: true, ForceNew: true}},
+		"activated": {
+			Schema: schema.Schema{
+				Computed:    true,
+				Description: "True once the gateway has been activated by its device.",
+			},
+		},
+		"expires_at": {
+			Schema: schema.Schema{
+				Computed: true,
+				Description: "When the token expires, in RFC 3339 format. Empty if `timeout_in_seconds` is not " +
+					"set. A new token is planned once it has expired, unless the gateway has been activated.",
+			},
+		},
+		"wait_for": {
+			Schema: schema.Schema{
+				Optional: true,
+				Description: "Wait on create until the gateway is `activated` by its device, or until it is also " +
+					"`online` and reporting its status to the orchestrator. By default the token is returned " +
+					"without waiting.",
+			},
+			Validate: ValidateOneOf("activated", "online"),
+		},
 	})
+	if err != nil {
+		return nil, err
+	}
 	rt := _resourceGatewayActivate{Binder: binder, InputBinder: inputBinder}
 
 	return &schema.Resource{
@@ -89,6 +183,13 @@ func resourceGatewayActivate() *schema.Resource {
 		ReadContext:   rt.resourceGatewayActivateRead,
 		UpdateContext: rt.resourceGatewayActivateUpdate,
 		DeleteContext: rt.resourceGatewayActivateDelete,
-		Schema:        swaggerSchema,
-	}
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateComposite("gateway_id"),
+		},
+		CustomizeDiff: planExpiredToken,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+		},
+		Schema: swaggerSchema,
+	}, nil
 }
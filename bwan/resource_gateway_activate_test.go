@@ -0,0 +1,169 @@
+package bwan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastPolls shortens the waits between polls for the duration of the test.
+func fastPolls(t *testing.T) {
+	min, max := pollWaitMin, pollWaitMax
+	pollWaitMin, pollWaitMax = time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { pollWaitMin, pollWaitMax = min, max })
+}
+
+// activateGateway creates the activation of gw1, waiting as given.
+func activateGateway(t *testing.T, ctx context.Context, client *apiClient, waitFor string) (*schema.ResourceData, diag.Diagnostics) {
+	r := mustResource(t, resourceGatewayActivate)
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"gateway_id": "gw1",
+		"wait_for":   waitFor,
+	})
+
+	return d, r.CreateContext(ctx, d, client)
+}
+
+func TestGatewayActivateNoWait(t *testing.T) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"})
+
+	d, diags := activateGateway(t, context.Background(), client, "")
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "token-gw1", d.Get("token"))
+	assert.NotEmpty(t, d.Id())
+	assert.Equal(t, 0, api.count("GET"))
+}
+
+func TestGatewayActivateWaitActivated(t *testing.T) {
+	fastPolls(t)
+	api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"})
+	// The device comes up after a few polls.
+	polls := 0
+	api.afterGet = func(edge *swagger.Edge) {
+		polls++
+		edge.Activated = polls >= 3
+	}
+
+	d, diags := activateGateway(t, context.Background(), client, "activated")
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "token-gw1", d.Get("token"))
+	assert.Equal(t, "activated", d.Get("wait_for"))
+	assert.Equal(t, 4, api.count("GET"))
+}
+
+func TestGatewayActivateWaitOnline(t *testing.T) {
+	fastPolls(t)
+	before := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"})
+	// A status left over from an earlier activation does not count.
+	api.statuses["gw1"] = &swagger.EdgeStatusRef{
+		TimePublished: before,
+		Status:        &swagger.EdgeStatus{DsDevstatusGeneral: &swagger.EdgeStatusDsDevstatusGeneral{}},
+	}
+	polls := 0
+	api.afterGet = func(edge *swagger.Edge) {
+		polls++
+		edge.Activated = true
+		if polls >= 5 {
+			api.statuses["gw1"] = &swagger.EdgeStatusRef{
+				TimePublished: before.Add(time.Hour),
+				Status:        &swagger.EdgeStatus{DsDevstatusGeneral: &swagger.EdgeStatusDsDevstatusGeneral{DsgUptime: 10}},
+			}
+		}
+	}
+
+	_, diags := activateGateway(t, context.Background(), client, "online")
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 1, api.count("POST"))
+	assert.Equal(t, 6, api.count("GET"))
+}
+
+func TestGatewayActivateWaitTimeout(t *testing.T) {
+	fastPolls(t)
+	_, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	d, diags := activateGateway(t, ctx, client, "activated")
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "gave up waiting for gateway gw1 to be activated")
+	// The token is kept, so that the activation is tainted rather than lost.
+	assert.NotEmpty(t, d.Id())
+	assert.Equal(t, "token-gw1", d.Get("token"))
+}
+
+func TestGatewayActivateWaitGatewayDeleted(t *testing.T) {
+	fastPolls(t)
+	api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"})
+	api.afterGet = func(edge *swagger.Edge) {
+		delete(api.edges, edge.Id)
+	}
+
+	_, diags := activateGateway(t, context.Background(), client, "activated")
+	require.True(t, diags.HasError())
+	assert.Equal(t, "GetEdgeById failed: 404 Not Found", diags[0].Summary)
+}
+
+func TestGatewayActivateExpiry(t *testing.T) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"})
+	r := mustResource(t, resourceGatewayActivate)
+	raw := map[string]interface{}{
+		"gateway_id":         "gw1",
+		"timeout_in_seconds": 3600,
+	}
+	d := schema.TestResourceDataRaw(t, r.Schema, raw)
+	diags := r.CreateContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	expiresAt, err := time.Parse(time.RFC3339, d.Get("expires_at").(string))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+	assert.False(t, d.Get("activated").(bool))
+
+	diff := func(state *terraform.InstanceState, raw map[string]interface{}) *terraform.InstanceDiff {
+		diff, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), client)
+		require.NoError(t, err)
+		if diff == nil {
+			return &terraform.InstanceDiff{}
+		}
+		return diff
+	}
+
+	// A valid token is left alone.
+	state := d.State()
+	assert.Empty(t, diff(state, raw).Attributes)
+
+	// An expired one is replaced while the gateway is not activated.
+	state.Attributes["expires_at"] = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	expired := diff(state, raw)
+	assert.True(t, expired.RequiresNew())
+	assert.True(t, expired.Attributes["token"].NewComputed)
+
+	// Once activated, as found by a refresh, it no longer matters.
+	api.edges["gw1"].Activated = true
+	d = r.Data(state)
+	diags = r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.True(t, d.Get("activated").(bool))
+	assert.Empty(t, diff(d.State(), raw).Attributes)
+
+	// Changing the request asks for a new token.
+	changed := diff(d.State(), map[string]interface{}{
+		"gateway_id":         "gw1",
+		"timeout_in_seconds": 7200,
+	})
+	assert.True(t, changed.RequiresNew())
+
+	// The activation goes with its gateway.
+	delete(api.edges, "gw1")
+	diags = r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Empty(t, d.Id())
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
-	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -26,6 +25,27 @@ func (rt _resourceGatewayBgp) getExistingBgpPeer(bgpPeers []swagger.EdgeBgpConfi
 	return -1
 }
 
+var bgpPeerItem = edgeItem{
+	What:  "BGP peer",
+	List:  bgpPeersList,
+	Owner: "netskopebwan_gateway_bgp_peers",
+	Key:   "neighbor",
+	Keys: func(gateway *swagger.Edge) []string {
+		return itemKeys(gateway.BgpConfiguration, bgpPeerKey)
+	},
+}
+
+// item returns the edgeMutation.Item of a mutation of the peer.
+func (rt _resourceGatewayBgp) item(peer swagger.EdgeBgpConfiguration) func(gateway *swagger.Edge) interface{} {
+	return func(gateway *swagger.Edge) interface{} {
+		index := rt.getExistingBgpPeer(gateway.BgpConfiguration, peer)
+		if index < 0 {
+			return nil
+		}
+		return gateway.BgpConfiguration[index]
+	}
+}
+
 func (rt _resourceGatewayBgp) resourceGatewayBgpRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	var bgpConfig swagger.EdgeBgpConfiguration
@@ -36,20 +56,23 @@ func (rt _resourceGatewayBgp) resourceGatewayBgpRead(ctx context.Context, d *sch
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(bgpInput.GatewayId) > 0 {
-		gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, bgpInput.GatewayId, nil)
+		gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, bgpInput.GatewayId, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
+			if apiSvc.isNotFound(err, resp, "/edges/"+bgpInput.GatewayId) {
+				d.SetId("")
+				return diags
 			}
-			return diag.FromErr(err)
+			return apiError("GetEdgeById", resp, err, rt.Binder)
 		}
 		index := rt.getExistingBgpPeer(gateway.BgpConfiguration, bgpInput.EdgeBgpConfiguration)
-		if index >= 0 {
-			bgpConfig = gateway.BgpConfiguration[index]
+		if index < 0 {
+			d.SetId("")
+			return removedOutOfBand(gateway, fmt.Sprintf("BGP peer %q", bgpInput.Neighbor))
 		}
+		bgpConfig = gateway.BgpConfiguration[index]
 	} else {
 		return diag.FromErr(err)
 	}
@@ -60,7 +83,7 @@ func (rt _resourceGatewayBgp) resourceGatewayBgpRead(ctx context.Context, d *sch
 		return diag.FromErr(err)
 	}
 
-	d.SetId(utils.Hash(bgpConfig))
+	d.SetId(compositeId(bgpInput.GatewayId, bgpInput.Neighbor))
 	return diags
 }
 
@@ -75,44 +98,28 @@ func (rt _resourceGatewayBgp) resourceGatewayBgpUpdate(
 		return diag.FromErr(err)
 	}
 
-	lock := utils.Mutex.Get(bgpInput.GatewayId)
 	rt.fixupBgpConfig(&bgpInput.EdgeBgpConfiguration)
-	lock.Lock()
-	defer lock.Unlock()
-	apiSvc := m.(*swagger.APIClient)
-	gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, bgpInput.GatewayId, nil)
+	apiSvc := m.(*apiClient)
+	gateway, err := apiSvc.writer.update(ctx, bgpInput.GatewayId, edgeMutation{
+		Lists: []string{"bgpConfiguration"},
+		Item:  rt.item(bgpInput.EdgeBgpConfiguration),
+		What:  fmt.Sprintf("BGP peer %q", bgpInput.Neighbor),
+		Apply: func(gateway *swagger.Edge) error {
+			index := rt.getExistingBgpPeer(gateway.BgpConfiguration, bgpInput.EdgeBgpConfiguration)
+			if index >= 0 {
+				gateway.BgpConfiguration[index] = bgpInput.EdgeBgpConfiguration
+			} else {
+				gateway.BgpConfiguration = append(gateway.BgpConfiguration, bgpInput.EdgeBgpConfiguration)
+			}
+			return nil
+		},
+	})
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return edgeWriteError(err, rt.Binder)
 	}
-	existBgpConfig := gateway.BgpConfiguration
-	index := rt.getExistingBgpPeer(existBgpConfig, bgpInput.EdgeBgpConfiguration)
+	index := rt.getExistingBgpPeer(gateway.BgpConfiguration, bgpInput.EdgeBgpConfiguration)
 	if index >= 0 {
-		existBgpConfig[index] = bgpInput.EdgeBgpConfiguration
-	} else {
-		existBgpConfig = append(existBgpConfig, bgpInput.EdgeBgpConfiguration)
-	}
-
-	addGwInput := swagger.UpdateEdgeInput{
-		BgpConfiguration: existBgpConfig,
-	}
-
-	if len(bgpInput.GatewayId) > 0 {
-		gateway, _, err := apiSvc.EdgesApi.UpdateEdgeById(ctx, addGwInput, bgpInput.GatewayId, nil)
-		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
-		}
-		index := rt.getExistingBgpPeer(gateway.BgpConfiguration, bgpInput.EdgeBgpConfiguration)
-		if index >= 0 {
-			bgpConfig = gateway.BgpConfiguration[index]
-		}
-	} else {
-		return diag.FromErr(err)
+		bgpConfig = gateway.BgpConfiguration[index]
 	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, bgpConfig)
@@ -120,7 +127,7 @@ func (rt _resourceGatewayBgp) resourceGatewayBgpUpdate(
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	d.SetId(utils.Hash(bgpConfig))
+	d.SetId(compositeId(bgpInput.GatewayId, bgpInput.Neighbor))
 	return diags
 }
 
@@ -134,38 +141,26 @@ func (rt _resourceGatewayBgp) resourceGatewayBgpDelete(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
-	lock := utils.Mutex.Get(bgpInput.GatewayId)
-	lock.Lock()
-	defer lock.Unlock()
-	gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, bgpInput.GatewayId, nil)
-	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
-	}
-	existBgpConfig := gateway.BgpConfiguration
-	index := rt.getExistingBgpPeer(existBgpConfig, bgpInput.EdgeBgpConfiguration)
-
-	if index >= 0 {
-		existBgpConfig = append(existBgpConfig[:index], existBgpConfig[index+1:]...)
-		addGwInput := swagger.UpdateEdgeInput{
-			BgpConfiguration: existBgpConfig,
-		}
-		if len(bgpInput.GatewayId) > 0 {
-			_, _, err := apiSvc.EdgesApi.UpdateEdgeById(ctx, addGwInput, bgpInput.GatewayId, nil)
-			if err != nil {
-				if serr, ok := err.(swagger.GenericSwaggerError); ok {
-					return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-				}
-				return diag.FromErr(err)
+	apiSvc := m.(*apiClient)
+	_, err = apiSvc.writer.update(ctx, bgpInput.GatewayId, edgeMutation{
+		Lists: []string{"bgpConfiguration"},
+		Item:  rt.item(bgpInput.EdgeBgpConfiguration),
+		What:  fmt.Sprintf("BGP peer %q", bgpInput.Neighbor),
+		Apply: func(gateway *swagger.Edge) error {
+			index := rt.getExistingBgpPeer(gateway.BgpConfiguration, bgpInput.EdgeBgpConfiguration)
+			if index >= 0 {
+				gateway.BgpConfiguration = append(
+					gateway.BgpConfiguration[:index],
+					gateway.BgpConfiguration[index+1:]...,
+				)
 			}
-			d.SetId("")
-		} else {
-			return diag.FromErr(err)
-		}
+			return nil
+		},
+	})
+	if err != nil {
+		return edgeWriteError(err, rt.Binder)
 	}
+	d.SetId("")
 	return diags
 }
 
@@ -179,13 +174,17 @@ type resourceGatewayBgpInput struct {
 	swagger.EdgeBgpConfiguration
 }
 
-func resourceGatewayBgp() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(resourceGatewayBgpInput{}, Cfg{
+func resourceGatewayBgp() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(resourceGatewayBgpInput{}, Cfg{
 		"name":       {Schema: schema.Schema{Required: true}},
 		"gateway_id": {Schema: schema.Schema{Required: true}},
-		"neighbor":   {Schema: schema.Schema{Required: true}},
-		"remote_as":  {Schema: schema.Schema{Required: true}},
+		"neighbor":   {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4},
+		"remote_as":  {Schema: schema.Schema{Required: true}, Validate: ValidateASN},
+		"local_as":   {Validate: ValidateASN},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _resourceGatewayBgp{Binder: binder, InputBinder: inputBinder}
 
@@ -194,6 +193,12 @@ func resourceGatewayBgp() *schema.Resource {
 		ReadContext:   rt.resourceGatewayBgpRead,
 		UpdateContext: rt.resourceGatewayBgpUpdate,
 		DeleteContext: rt.resourceGatewayBgpDelete,
-		Schema:        swaggerSchema,
-	}
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateComposite("gateway_id", "neighbor"),
+		},
+		CustomizeDiff:  bgpPeerItem.checkNew(),
+		Schema:         swaggerSchema,
+		SchemaVersion:  1,
+		StateUpgraders: compositeIdStateUpgraders(swaggerSchema, "gateway_id", "neighbor"),
+	}, nil
 }
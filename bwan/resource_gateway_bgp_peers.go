@@ -0,0 +1,153 @@
+package bwan
+
+import (
+	"context"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const bgpPeersList = "BGP peers"
+
+// hashBgpPeer identifies peers by neighbor only, so that a changed peer is
+// planned as an update of that peer rather than a removal and an addition.
+func hashBgpPeer(v interface{}) int {
+	return schema.HashString(v.(map[string]interface{})["neighbor"])
+}
+
+func bgpPeerKey(peer swagger.EdgeBgpConfiguration) string {
+	return peer.Neighbor
+}
+
+func (rt _resourceGatewayBgpPeers) resourceGatewayBgpPeersRead(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	edgeInput, err := ApplyBinderInputResourceData[resourceGatewayBgpPeersInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	apiSvc := m.(*apiClient)
+
+	gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
+	if err != nil {
+		if apiSvc.isNotFound(err, resp, "/edges/"+edgeInput.GatewayId) {
+			d.SetId("")
+			return diags
+		}
+		return apiError("GetEdgeById", resp, err, rt.Binder)
+	}
+
+	err = ApplyBinderResourceData(rt.Binder, d, resourceGatewayBgpPeersInput{
+		GatewayId: edgeInput.GatewayId,
+		Peers:     orderByKey(edgeInput.Peers, gateway.BgpConfiguration, bgpPeerKey),
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(edgeInput.GatewayId)
+	return diags
+}
+
+func (rt _resourceGatewayBgpPeers) resourceGatewayBgpPeersUpdate(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	edgeInput, err := ApplyBinderInputResourceData[resourceGatewayBgpPeersInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	for i := range edgeInput.Peers {
+		_resourceGatewayBgp{}.fixupBgpConfig(&edgeInput.Peers[i])
+	}
+
+	apiSvc := m.(*apiClient)
+	gateway, err := apiSvc.writer.update(ctx, edgeInput.GatewayId, edgeMutation{
+		Lists: []string{"bgpConfiguration"},
+		Apply: func(gateway *swagger.Edge) error {
+			gateway.BgpConfiguration = edgeInput.Peers
+			return nil
+		},
+	})
+	if err != nil {
+		return edgeWriteError(err, rt.Binder)
+	}
+
+	err = ApplyBinderResourceData(rt.Binder, d, resourceGatewayBgpPeersInput{
+		GatewayId: edgeInput.GatewayId,
+		Peers:     orderByKey(edgeInput.Peers, gateway.BgpConfiguration, bgpPeerKey),
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(edgeInput.GatewayId)
+	return diags
+}
+
+func (rt _resourceGatewayBgpPeers) resourceGatewayBgpPeersDelete(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	edgeInput, err := ApplyBinderInputResourceData[resourceGatewayBgpPeersInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	apiSvc := m.(*apiClient)
+	_, err = apiSvc.writer.update(ctx, edgeInput.GatewayId, edgeMutation{
+		Lists: []string{"bgpConfiguration"},
+		Apply: func(gateway *swagger.Edge) error {
+			gateway.BgpConfiguration = nil
+			return nil
+		},
+	})
+	if err != nil && !apiSvc.edgeGone(err, edgeInput.GatewayId) {
+		return edgeWriteError(err, rt.Binder)
+	}
+
+	d.SetId("")
+	return diags
+}
+
+type _resourceGatewayBgpPeers struct {
+	Binder      []FieldBinder
+	InputBinder []FieldBinder
+}
+
+type resourceGatewayBgpPeersInput struct {
+	GatewayId string
+	Peers     []swagger.EdgeBgpConfiguration `json:"peer"`
+}
+
+func resourceGatewayBgpPeers() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(resourceGatewayBgpPeersInput{}, Cfg{
+		"gateway_id":     {Schema: schema.Schema{Required: true, ForceNew: true}},
+		"peer":           {Schema: schema.Schema{Type: schema.TypeSet, Optional: true, Set: hashBgpPeer}},
+		"peer.name":      {Schema: schema.Schema{Required: true}},
+		"peer.neighbor":  {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4},
+		"peer.remote_as": {Schema: schema.Schema{Required: true}, Validate: ValidateASN},
+		"peer.local_as":  {Validate: ValidateASN},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rt := _resourceGatewayBgpPeers{Binder: binder, InputBinder: inputBinder}
+
+	return &schema.Resource{
+		CreateContext: rt.resourceGatewayBgpPeersUpdate,
+		ReadContext:   rt.resourceGatewayBgpPeersRead,
+		UpdateContext: rt.resourceGatewayBgpPeersUpdate,
+		DeleteContext: rt.resourceGatewayBgpPeersDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateComposite("gateway_id"),
+		},
+		CustomizeDiff: uniqueKeys("peer", "neighbor"),
+		Schema:        swaggerSchema,
+	}, nil
+}
@@ -0,0 +1,173 @@
+package bwan
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testPeer(neighbor string, remoteAS int) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      "peer-" + neighbor,
+		"neighbor":  neighbor,
+		"remote_as": remoteAS,
+	}
+}
+
+func TestResourceGatewayBgpPeers(t *testing.T) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{
+		Id: "gw1",
+		BgpConfiguration: []swagger.EdgeBgpConfiguration{
+			{Name: "manual", Neighbor: "10.9.9.9", RemoteAS: 65009},
+		},
+	})
+
+	r := mustResource(t, resourceGatewayBgpPeers)
+	var peers []interface{}
+	for i := 1; i <= 20; i++ {
+		peers = append(peers, testPeer(fmt.Sprintf("10.0.0.%d", i), 65000+i))
+	}
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"gateway_id": "gw1",
+		"peer":       peers,
+	})
+
+	// The peer added elsewhere is replaced along with the rest.
+	diags := r.CreateContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, "gw1", d.Id())
+	assert.Equal(t, 1, api.count("PUT"))
+	assert.Len(t, api.edge("gw1").BgpConfiguration, 20)
+	assert.NotContains(t, itemKeys(api.edge("gw1").BgpConfiguration, bgpPeerKey), "10.9.9.9")
+	for _, peer := range api.edge("gw1").BgpConfiguration {
+		assert.EqualValues(t, 400, peer.LocalAS)
+	}
+	assert.Equal(t, 20, d.Get("peer").(*schema.Set).Len())
+
+	// Peers added elsewhere are read, so they are planned for removal.
+	api.edges["gw1"].BgpConfiguration = append(api.edges["gw1"].BgpConfiguration,
+		swagger.EdgeBgpConfiguration{Name: "manual", Neighbor: "10.9.9.9", RemoteAS: 65009})
+	diags = r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 21, d.Get("peer").(*schema.Set).Len())
+
+	diff, err := r.SimpleDiff(context.Background(), d.State(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"gateway_id": "gw1",
+		"peer":       peers,
+	}), client)
+	require.NoError(t, err)
+	removed := fmt.Sprintf("peer.%d.neighbor", hashBgpPeer(testPeer("10.9.9.9", 0)))
+	require.Contains(t, diff.Attributes, removed)
+	assert.True(t, diff.Attributes[removed].NewRemoved)
+
+	state, diags := r.Apply(context.Background(), d.State(), diff, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "20", state.Attributes["peer.#"])
+	assert.Equal(t, 2, api.count("PUT"))
+	assert.Len(t, api.edge("gw1").BgpConfiguration, 20)
+	assert.NotContains(t, itemKeys(api.edge("gw1").BgpConfiguration, bgpPeerKey), "10.9.9.9")
+
+	diags = r.DeleteContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, 3, api.count("PUT"))
+	assert.Empty(t, api.edge("gw1").BgpConfiguration)
+}
+
+func TestResourceGatewayBgpPeersImport(t *testing.T) {
+	_, client := newFakeEdgeAPI(t, swagger.Edge{
+		Id: "gw1",
+		BgpConfiguration: []swagger.EdgeBgpConfiguration{
+			{Name: "dc", Neighbor: "10.0.0.1", RemoteAS: 65001},
+			{Name: "cloud", Neighbor: "10.0.0.2", RemoteAS: 65002},
+		},
+	})
+	r := mustResource(t, resourceGatewayBgpPeers)
+
+	d := r.Data(nil)
+	d.SetId("gw1")
+	imported, err := r.Importer.StateContext(context.Background(), d, client)
+	require.NoError(t, err)
+
+	d = imported[0]
+	diags := r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, 2, d.Get("peer").(*schema.Set).Len())
+	assert.Equal(t, "cloud", d.Get(fmt.Sprintf("peer.%d.name", hashBgpPeer(testPeer("10.0.0.2", 0)))))
+}
+
+func TestResourceGatewayBgpPeersDiff(t *testing.T) {
+	_, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"})
+
+	r := mustResource(t, resourceGatewayBgpPeers)
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"gateway_id": "gw1",
+		"peer": []interface{}{
+			testPeer("10.0.0.1", 65001),
+			testPeer("10.0.0.2", 65002),
+		},
+	})
+	diags := r.CreateContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	diff, err := r.SimpleDiff(context.Background(), d.State(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"gateway_id": "gw1",
+		"peer": []interface{}{
+			testPeer("10.0.0.1", 65101),
+			testPeer("10.0.0.3", 65003),
+		},
+	}), client)
+	require.NoError(t, err)
+
+	changed := fmt.Sprintf("peer.%d.remote_as", hashBgpPeer(testPeer("10.0.0.1", 0)))
+	require.Contains(t, diff.Attributes, changed)
+	assert.Equal(t, "65001", diff.Attributes[changed].Old)
+	assert.Equal(t, "65101", diff.Attributes[changed].New)
+
+	removed := fmt.Sprintf("peer.%d.neighbor", hashBgpPeer(testPeer("10.0.0.2", 0)))
+	require.Contains(t, diff.Attributes, removed)
+	assert.True(t, diff.Attributes[removed].NewRemoved)
+
+	added := fmt.Sprintf("peer.%d.neighbor", hashBgpPeer(testPeer("10.0.0.3", 0)))
+	require.Contains(t, diff.Attributes, added)
+	assert.Equal(t, "10.0.0.3", diff.Attributes[added].New)
+
+	// The per-peer resource cannot create a peer the gateway has.
+	single := mustResource(t, resourceGatewayBgp)
+	_, err = single.SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(
+		map[string]interface{}{"gateway_id": "gw1", "name": "dc", "neighbor": "10.0.0.1", "remote_as": 65001},
+	), client)
+	assert.ErrorContains(t, err, `BGP peer "10.0.0.1" already exists on gateway gw1: import it, `+
+		`or declare it in netskopebwan_gateway_bgp_peers if that resource manages the BGP peers of the gateway`)
+
+	_, err = single.SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(
+		map[string]interface{}{"gateway_id": "gw1", "name": "dc", "neighbor": "10.0.0.4", "remote_as": 65004},
+	), client)
+	assert.NoError(t, err)
+}
+
+func TestCtyKeyValues(t *testing.T) {
+	peer := cty.ObjectVal(map[string]cty.Value{
+		"neighbor":  cty.StringVal("10.0.0.1"),
+		"remote_as": cty.NumberIntVal(65001),
+		"bfd":       cty.True,
+		"name":      cty.NullVal(cty.String),
+	})
+	assert.Equal(t, []string{"10.0.0.1", "65001", "true", ""},
+		ctyKeyValues(peer, []string{"neighbor", "remote_as", "bfd", "name"}))
+
+	unknown := cty.ObjectVal(map[string]cty.Value{
+		"neighbor": cty.UnknownVal(cty.String),
+	})
+	assert.Nil(t, ctyKeyValues(unknown, []string{"neighbor"}))
+}
@@ -3,12 +3,13 @@ package bwan
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
-	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func (rt _resourceGatewayInterface) fixupInterfaceConfig(
@@ -37,6 +38,27 @@ func (rt _resourceGatewayInterface) fixupInterfaceConfig(
 	}
 }
 
+func (rt _resourceGatewayInterface) getExistingInterface(
+	interfaces []swagger.InterfaceSettings, name string) int {
+	for index, intf := range interfaces {
+		if intf.Name == name {
+			return index
+		}
+	}
+	return -1
+}
+
+// item returns the edgeMutation.Item of a mutation of the named interface.
+func (rt _resourceGatewayInterface) item(name string) func(gateway *swagger.Edge) interface{} {
+	return func(gateway *swagger.Edge) interface{} {
+		index := rt.getExistingInterface(gateway.Interfaces, name)
+		if index < 0 {
+			return nil
+		}
+		return gateway.Interfaces[index]
+	}
+}
+
 func (rt _resourceGatewayInterface) resourceGatewayInterfaceRead(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
@@ -47,16 +69,19 @@ func (rt _resourceGatewayInterface) resourceGatewayInterfaceRead(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(intfInput.GatewayId) > 0 && len(intfInput.InterfaceSettings.Name) > 0 {
-		intf, _, err = apiSvc.EdgesApi.GetEdgeIfByName(
+		var resp *http.Response
+		intf, resp, err = apiSvc.EdgesApi.GetEdgeIfByName(
 			ctx, intfInput.GatewayId, intfInput.InterfaceSettings.Name, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
+			if apiSvc.isNotFound(err, resp,
+				"/edges/"+intfInput.GatewayId+"/interfaces/"+intfInput.InterfaceSettings.Name) {
+				d.SetId("")
+				return diags
 			}
-			return diag.FromErr(err)
+			return apiError("GetEdgeIfByName", resp, err, rt.Binder)
 		}
 	} else {
 		return diag.FromErr(err)
@@ -68,7 +93,7 @@ func (rt _resourceGatewayInterface) resourceGatewayInterfaceRead(
 		return diag.FromErr(err)
 	}
 
-	d.SetId(utils.Hash(intf))
+	d.SetId(compositeId(intfInput.GatewayId, intfInput.InterfaceSettings.Name))
 	return diags
 }
 
@@ -82,21 +107,26 @@ func (rt _resourceGatewayInterface) resourceGatewayInterfaceUpdate(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(intfInput.GatewayId) > 0 && len(intfInput.InterfaceSettings.Name) > 0 {
-		lock := utils.Mutex.Get(intfInput.GatewayId)
-		lock.Lock()
-		defer lock.Unlock()
 		rt.fixupInterfaceConfig(&intfInput.InterfaceSettings)
-		gateway, _, err := apiSvc.EdgesApi.UpdateEdgeIfByName(
-			ctx, intfInput.InterfaceSettings, intfInput.GatewayId, intfInput.InterfaceSettings.Name, nil)
-
+		gateway, err := apiSvc.writer.update(ctx, intfInput.GatewayId, edgeMutation{
+			Lists: []string{"interfaces"},
+			Item:  rt.item(intfInput.InterfaceSettings.Name),
+			What:  fmt.Sprintf("interface %q", intfInput.InterfaceSettings.Name),
+			Apply: func(gateway *swagger.Edge) error {
+				index := rt.getExistingInterface(gateway.Interfaces, intfInput.InterfaceSettings.Name)
+				if index < 0 {
+					return fmt.Errorf("interface %q does not exist on gateway %s",
+						intfInput.InterfaceSettings.Name, intfInput.GatewayId)
+				}
+				gateway.Interfaces[index] = intfInput.InterfaceSettings
+				return nil
+			},
+		})
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
+			return edgeWriteError(err, rt.Binder)
 		}
 		for _, i := range gateway.Interfaces {
 			if i.Name == intfInput.InterfaceSettings.Name {
@@ -104,7 +134,7 @@ func (rt _resourceGatewayInterface) resourceGatewayInterfaceUpdate(
 			}
 		}
 
-		d.SetId(utils.Hash(intf))
+		d.SetId(compositeId(intfInput.GatewayId, intfInput.InterfaceSettings.Name))
 		err = ApplyBinderResourceData(rt.Binder, d, intf)
 		if err != nil {
 			return diag.FromErr(err)
@@ -126,35 +156,24 @@ func (rt _resourceGatewayInterface) resourceGatewayInterfaceDelete(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(intfInput.GatewayId) > 0 && len(intfInput.InterfaceSettings.Name) > 0 {
-		lock := utils.Mutex.Get(intfInput.GatewayId)
-		lock.Lock()
-		defer lock.Unlock()
-		intf, _, err = apiSvc.EdgesApi.GetEdgeIfByName(
-			ctx, intfInput.GatewayId, intfInput.InterfaceSettings.Name, nil)
-		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
-		}
-
 		// We cant delete the interface. So we are disabling it.
-		intfInput.InterfaceSettings.IsDisabled = true
-
-		gateway, _, err := apiSvc.EdgesApi.UpdateEdgeIfByName(ctx,
-			intf,
-			intfInput.GatewayId,
-			intf.Name,
-			nil,
-		)
+		gateway, err := apiSvc.writer.update(ctx, intfInput.GatewayId, edgeMutation{
+			Lists: []string{"interfaces"},
+			Item:  rt.item(intfInput.InterfaceSettings.Name),
+			What:  fmt.Sprintf("interface %q", intfInput.InterfaceSettings.Name),
+			Apply: func(gateway *swagger.Edge) error {
+				index := rt.getExistingInterface(gateway.Interfaces, intfInput.InterfaceSettings.Name)
+				if index >= 0 {
+					gateway.Interfaces[index].IsDisabled = true
+				}
+				return nil
+			},
+		})
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
+			return edgeWriteError(err, rt.Binder)
 		}
 		for _, i := range gateway.Interfaces {
 			if i.Name == intfInput.InterfaceSettings.Name {
@@ -184,12 +203,29 @@ type resourceGatewayInterfaceInput struct {
 	swagger.InterfaceSettings
 }
 
-func resourceGatewayInterface() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(resourceGatewayInterfaceInput{}, Cfg{
-		"name":        {Schema: schema.Schema{Required: true}},
-		"gateway_id":  {Schema: schema.Schema{Required: true}},
-		"is_disabled": {Schema: schema.Schema{Required: true}},
+func resourceGatewayInterface() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(resourceGatewayInterfaceInput{}, Cfg{
+		"name":                         {Schema: schema.Schema{Required: true}},
+		"gateway_id":                   {Schema: schema.Schema{Required: true}},
+		"is_disabled":                  {Schema: schema.Schema{Required: true}},
+		"mode":                         {SpecEnum: true},
+		"type":                         {SpecEnum: true},
+		"mtu":                          {Validate: ValidateMTU},
+		"mtu_discovery":                {SpecEnum: true},
+		"mac_addr":                     {Validate: ValidateRegexp(`^([0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2}$`, "a MAC address such as 00:11:22:33:44:55")},
+		"vlan":                         {Validate: validation.IntBetween(0, 4094)},
+		"allowed_vlans":                {Validate: ValidateVLAN},
+		"addresses.address_assignment": {SpecEnum: true},
+		"addresses.address_family":     {SpecEnum: true},
+		"addresses.address":            {Validate: ValidateIPv4},
+		"addresses.mask":               {Validate: ValidateIPv4},
+		"addresses.gateway":            {Validate: ValidateIPv4},
+		"addresses.dns_primary":        {Validate: ValidateIPv4},
+		"addresses.dns_secondary":      {Validate: ValidateIPv4},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _resourceGatewayInterface{Binder: binder, InputBinder: inputBinder}
 
@@ -198,6 +234,11 @@ func resourceGatewayInterface() *schema.Resource {
 		ReadContext:   rt.resourceGatewayInterfaceRead,
 		UpdateContext: rt.resourceGatewayInterfaceUpdate,
 		DeleteContext: rt.resourceGatewayInterfaceDelete,
-		Schema:        swaggerSchema,
-	}
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateComposite("gateway_id", "name"),
+		},
+		Schema:         swaggerSchema,
+		SchemaVersion:  1,
+		StateUpgraders: compositeIdStateUpgraders(swaggerSchema, "gateway_id", "name"),
+	}, nil
 }
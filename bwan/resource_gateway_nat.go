@@ -11,6 +11,16 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// item returns the edgeMutation.Item of a mutation of the rule.
+func (rt _resourceGatewayNat) item(input resourceGatewayNatInput) func(gateway *swagger.Edge) interface{} {
+	return func(gateway *swagger.Edge) interface{} {
+		if rule, ok := rt.GetConfig(gateway, input); ok {
+			return rule
+		}
+		return nil
+	}
+}
+
 func (rt _resourceGatewayNat) resourceGatewayNatRead(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
@@ -22,17 +32,23 @@ func (rt _resourceGatewayNat) resourceGatewayNatRead(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(edgeInput.GatewayId) > 0 {
-		gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
+		gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
+			if apiSvc.isNotFound(err, resp, "/edges/"+edgeInput.GatewayId) {
+				d.SetId("")
+				return diags
 			}
-			return diag.FromErr(err)
+			return apiError("GetEdgeById", resp, err, rt.Binder)
+		}
+		var ok bool
+		natConfig, ok = rt.GetConfig(&gateway, edgeInput)
+		if !ok {
+			d.SetId("")
+			return removedOutOfBand(gateway, fmt.Sprintf("%s %q", rt.Kind, edgeInput.Name))
 		}
-		natConfig = rt.GetConfig(&gateway, edgeInput)
 	} else {
 		return diag.FromErr(err)
 	}
@@ -42,7 +58,11 @@ func (rt _resourceGatewayNat) resourceGatewayNatRead(
 		return diag.FromErr(err)
 	}
 
-	d.SetId(utils.Hash(natConfig))
+	// Rules of states upgraded without a name keep their old ID until
+	// they are given one.
+	if edgeInput.Name != "" {
+		d.SetId(compositeId(edgeInput.GatewayId, edgeInput.Name))
+	}
 	return diags
 }
 
@@ -57,44 +77,46 @@ func (rt _resourceGatewayNat) resourceGatewayNatUpdate(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
-	lock := utils.Mutex.Get(edgeInput.GatewayId)
-	lock.Lock()
-	defer lock.Unlock()
-	gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
-	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
+	apiSvc := m.(*apiClient)
+	// Rules are found by name, so a renamed rule, e.g. a rule of an upgraded
+	// state that had none, replaces the rule of its prior name. Rules
+	// without a name are found by the prior values of the attributes that
+	// utils.GetExistingNat matches them on.
+	prior := edgeInput
+	if d.Id() != "" && d.HasChange("name") {
+		old := func(k string) interface{} {
+			v, _ := d.GetChange(k)
+			return v
 		}
-		return diag.FromErr(err)
-	}
-
-	rt.AddConfig(&gateway, edgeInput)
-
-	addGwInput := swagger.UpdateEdgeInput{
-		One2OneNatRules:        gateway.One2OneNatRules,
-		PortForwardingNatRules: gateway.PortForwardingNatRules,
+		prior.Name = old("name").(string)
+		prior.UpLinkIfName = old("up_link_if_name").(string)
+		prior.PublicIp = old("public_ip").(string)
+		prior.PublicPort = int32(old("public_port").(int))
+		prior.LanIp = old("lan_ip").(string)
 	}
-
-	if len(edgeInput.GatewayId) > 0 {
-		gateway, _, err := apiSvc.EdgesApi.UpdateEdgeById(ctx, addGwInput, edgeInput.GatewayId, nil)
-		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
+	gateway, err := apiSvc.writer.update(ctx, edgeInput.GatewayId, edgeMutation{
+		Lists: []string{rt.Key},
+		Item:  rt.item(edgeInput),
+		What:  fmt.Sprintf("%s %q", rt.Kind, edgeInput.Name),
+		Apply: func(gateway *swagger.Edge) error {
+			if prior.Name != edgeInput.Name {
+				rt.DeleteConfig(gateway, prior)
 			}
-			return diag.FromErr(err)
-		}
-		natConfig = rt.GetConfig(&gateway, edgeInput)
-	} else {
-		return diag.FromErr(err)
+			rt.AddConfig(gateway, edgeInput)
+			return nil
+		},
+	})
+	if err != nil {
+		return edgeWriteError(err, rt.Binder)
 	}
+	natConfig, _ = rt.GetConfig(&gateway, edgeInput)
 
 	err = ApplyBinderResourceData(rt.Binder, d, natConfig)
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	d.SetId(utils.Hash(natConfig))
+	d.SetId(compositeId(edgeInput.GatewayId, edgeInput.Name))
 	return diags
 }
 
@@ -109,43 +131,33 @@ func (rt _resourceGatewayNat) resourceGatewayNatDelete(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
-	lock := utils.Mutex.Get(edgeInput.GatewayId)
-	lock.Lock()
-	defer lock.Unlock()
-	gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
+	apiSvc := m.(*apiClient)
+	_, err = apiSvc.writer.update(ctx, edgeInput.GatewayId, edgeMutation{
+		Lists: []string{rt.Key},
+		Item:  rt.item(edgeInput),
+		What:  fmt.Sprintf("%s %q", rt.Kind, edgeInput.Name),
+		Apply: func(gateway *swagger.Edge) error {
+			rt.DeleteConfig(gateway, edgeInput)
+			return nil
+		},
+	})
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
-	}
-	rt.DeleteConfig(&gateway, edgeInput)
-	addGwInput := swagger.UpdateEdgeInput{
-		One2OneNatRules:        gateway.One2OneNatRules,
-		PortForwardingNatRules: gateway.PortForwardingNatRules,
-	}
-
-	if len(edgeInput.GatewayId) > 0 {
-		_, _, err := apiSvc.EdgesApi.UpdateEdgeById(ctx, addGwInput, edgeInput.GatewayId, nil)
-		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
-		}
-	} else {
-		return diag.FromErr(err)
+		return edgeWriteError(err, rt.Binder)
 	}
 	d.SetId("")
 	return diags
 }
 
 type _resourceGatewayNat struct {
-	Binder       []FieldBinder
-	InputBinder  []FieldBinder
+	Binder      []FieldBinder
+	InputBinder []FieldBinder
+	Kind        string
+	// Key is the name of the list in the edge JSON.
+	Key string
+	// Rules describes the list for checking new rules.
+	Rules        edgeItem
 	DeleteConfig func(*swagger.Edge, resourceGatewayNatInput)
-	GetConfig    func(*swagger.Edge, resourceGatewayNatInput) swagger.InboundNatRule
+	GetConfig    func(*swagger.Edge, resourceGatewayNatInput) (swagger.InboundNatRule, bool)
 	AddConfig    func(*swagger.Edge, resourceGatewayNatInput)
 }
 
@@ -154,18 +166,33 @@ type resourceGatewayNatInput struct {
 	swagger.InboundNatRule
 }
 
-func resourceGatewayNat() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(resourceGatewayNatInput{}, Cfg{
+func resourceGatewayNat() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(resourceGatewayNatInput{}, Cfg{
 		"gateway_id":      {Schema: schema.Schema{Required: true}},
-		"public_ip":       {Schema: schema.Schema{Required: true}},
+		"name":            {Schema: schema.Schema{Required: true}},
+		"public_ip":       {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4},
 		"up_link_if_name": {Schema: schema.Schema{Required: true}},
-		"lan_ip":          {Schema: schema.Schema{Required: true}},
+		"lan_ip":          {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4},
 		"bi_directional":  {Schema: schema.Schema{Required: true}},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _resourceGatewayNat{
 		Binder:      binder,
 		InputBinder: inputBinder,
+		Kind:        "NAT rule",
+		Key:         "one2OneNatRules",
+		Rules: edgeItem{
+			What:  "NAT rule",
+			List:  natRulesList,
+			Owner: "netskopebwan_gateway_nat_rules",
+			Key:   "name",
+			Keys: func(gateway *swagger.Edge) []string {
+				return itemKeys(gateway.One2OneNatRules, natRuleKey)
+			},
+		},
 		DeleteConfig: func(gateway *swagger.Edge, edgeInput resourceGatewayNatInput) {
 			index := utils.GetExistingNat(gateway.One2OneNatRules, edgeInput.InboundNatRule)
 			if index >= 0 {
@@ -177,12 +204,12 @@ func resourceGatewayNat() *schema.Resource {
 		},
 		GetConfig: func(gateway *swagger.Edge,
 			edgeInput resourceGatewayNatInput) (
-			natConfig swagger.InboundNatRule) {
+			natConfig swagger.InboundNatRule, ok bool) {
 			index := utils.GetExistingNat(gateway.One2OneNatRules, edgeInput.InboundNatRule)
 			if index >= 0 {
-				natConfig = gateway.One2OneNatRules[index]
+				return gateway.One2OneNatRules[index], true
 			}
-			return natConfig
+			return natConfig, false
 		},
 		AddConfig: func(gateway *swagger.Edge, edgeInput resourceGatewayNatInput) {
 			index := utils.GetExistingNat(gateway.One2OneNatRules, edgeInput.InboundNatRule)
@@ -202,28 +229,49 @@ func resourceGatewayNat() *schema.Resource {
 		ReadContext:   rt.resourceGatewayNatRead,
 		UpdateContext: rt.resourceGatewayNatUpdate,
 		DeleteContext: rt.resourceGatewayNatDelete,
-		Schema:        swaggerSchema,
-	}
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateComposite("gateway_id", "name"),
+		},
+		CustomizeDiff:  rt.Rules.checkNew(),
+		Schema:         swaggerSchema,
+		SchemaVersion:  1,
+		StateUpgraders: compositeIdStateUpgraders(swaggerSchema, "gateway_id", "name"),
+	}, nil
 }
 
-func resourceGatewayPortForward() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(resourceGatewayNatInput{}, Cfg{
+func resourceGatewayPortForward() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(resourceGatewayNatInput{}, Cfg{
 		"gateway_id":      {Schema: schema.Schema{Required: true}},
-		"public_ip":       {Schema: schema.Schema{Required: true}},
+		"name":            {Schema: schema.Schema{Required: true}},
+		"public_ip":       {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4},
 		"up_link_if_name": {Schema: schema.Schema{Required: true}},
-		"lan_ip":          {Schema: schema.Schema{Required: true}},
+		"lan_ip":          {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4},
 		"bi_directional":  {Schema: schema.Schema{Required: true}},
-		"lan_port":        {Schema: schema.Schema{Required: true}},
-		"public_port":     {Schema: schema.Schema{Required: true}},
+		"lan_port":        {Schema: schema.Schema{Required: true}, Validate: ValidatePort},
+		"public_port":     {Schema: schema.Schema{Required: true}, Validate: ValidatePort},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _resourceGatewayNat{
 		Binder:      binder,
 		InputBinder: inputBinder,
+		Kind:        "Port forwarding rule",
+		Key:         "portForwardingNatRules",
+		Rules: edgeItem{
+			What:  "port forwarding rule",
+			List:  portForwardRulesList,
+			Owner: "netskopebwan_gateway_port_forward_rules",
+			Key:   "name",
+			Keys: func(gateway *swagger.Edge) []string {
+				return itemKeys(gateway.PortForwardingNatRules, natRuleKey)
+			},
+		},
 		DeleteConfig: func(gateway *swagger.Edge, edgeInput resourceGatewayNatInput) {
 			index := utils.GetExistingNat(gateway.PortForwardingNatRules, edgeInput.InboundNatRule)
 			if index >= 0 {
-				gateway.One2OneNatRules = append(
+				gateway.PortForwardingNatRules = append(
 					gateway.PortForwardingNatRules[:index],
 					gateway.PortForwardingNatRules[index+1:]...,
 				)
@@ -231,12 +279,12 @@ func resourceGatewayPortForward() *schema.Resource {
 		},
 		GetConfig: func(gateway *swagger.Edge,
 			edgeInput resourceGatewayNatInput) (
-			natConfig swagger.InboundNatRule) {
+			natConfig swagger.InboundNatRule, ok bool) {
 			index := utils.GetExistingNat(gateway.PortForwardingNatRules, edgeInput.InboundNatRule)
 			if index >= 0 {
-				natConfig = gateway.PortForwardingNatRules[index]
+				return gateway.PortForwardingNatRules[index], true
 			}
-			return natConfig
+			return natConfig, false
 		},
 		AddConfig: func(gateway *swagger.Edge, edgeInput resourceGatewayNatInput) {
 			index := utils.GetExistingNat(gateway.PortForwardingNatRules, edgeInput.InboundNatRule)
@@ -256,6 +304,12 @@ func resourceGatewayPortForward() *schema.Resource {
 		ReadContext:   rt.resourceGatewayNatRead,
 		UpdateContext: rt.resourceGatewayNatUpdate,
 		DeleteContext: rt.resourceGatewayNatDelete,
-		Schema:        swaggerSchema,
-	}
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateComposite("gateway_id", "name"),
+		},
+		CustomizeDiff:  rt.Rules.checkNew(),
+		Schema:         swaggerSchema,
+		SchemaVersion:  1,
+		StateUpgraders: compositeIdStateUpgraders(swaggerSchema, "gateway_id", "name"),
+	}, nil
 }
@@ -0,0 +1,203 @@
+package bwan
+
+import (
+	"context"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	natRulesList         = "NAT rules"
+	portForwardRulesList = "port forwarding rules"
+)
+
+func natRuleKey(rule swagger.InboundNatRule) string {
+	return rule.Name
+}
+
+func (rt _resourceGatewayNatRules) setNatRules(
+	d *schema.ResourceData, prior resourceGatewayNatRulesInput, gateway swagger.Edge) error {
+	return ApplyBinderResourceData(rt.Binder, d, resourceGatewayNatRulesInput{
+		GatewayId: prior.GatewayId,
+		Rules:     orderByKey(prior.Rules, rt.GetRules(&gateway), natRuleKey),
+	})
+}
+
+func (rt _resourceGatewayNatRules) resourceGatewayNatRulesRead(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	edgeInput, err := ApplyBinderInputResourceData[resourceGatewayNatRulesInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	apiSvc := m.(*apiClient)
+
+	gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
+	if err != nil {
+		if apiSvc.isNotFound(err, resp, "/edges/"+edgeInput.GatewayId) {
+			d.SetId("")
+			return diags
+		}
+		return apiError("GetEdgeById", resp, err, rt.Binder)
+	}
+
+	err = rt.setNatRules(d, edgeInput, gateway)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(edgeInput.GatewayId)
+	return diags
+}
+
+func (rt _resourceGatewayNatRules) resourceGatewayNatRulesUpdate(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	edgeInput, err := ApplyBinderInputResourceData[resourceGatewayNatRulesInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	apiSvc := m.(*apiClient)
+	gateway, err := apiSvc.writer.update(ctx, edgeInput.GatewayId, edgeMutation{
+		Lists: []string{rt.Key},
+		Apply: func(gateway *swagger.Edge) error {
+			rt.SetRules(gateway, edgeInput.Rules)
+			return nil
+		},
+	})
+	if err != nil {
+		return edgeWriteError(err, rt.Binder)
+	}
+
+	err = rt.setNatRules(d, edgeInput, gateway)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(edgeInput.GatewayId)
+	return diags
+}
+
+func (rt _resourceGatewayNatRules) resourceGatewayNatRulesDelete(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	edgeInput, err := ApplyBinderInputResourceData[resourceGatewayNatRulesInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	apiSvc := m.(*apiClient)
+	_, err = apiSvc.writer.update(ctx, edgeInput.GatewayId, edgeMutation{
+		Lists: []string{rt.Key},
+		Apply: func(gateway *swagger.Edge) error {
+			rt.SetRules(gateway, nil)
+			return nil
+		},
+	})
+	if err != nil && !apiSvc.edgeGone(err, edgeInput.GatewayId) {
+		return edgeWriteError(err, rt.Binder)
+	}
+
+	d.SetId("")
+	return diags
+}
+
+type _resourceGatewayNatRules struct {
+	Binder      []FieldBinder
+	InputBinder []FieldBinder
+	// Key is the name of the list in the edge JSON.
+	Key      string
+	GetRules func(*swagger.Edge) []swagger.InboundNatRule
+	SetRules func(*swagger.Edge, []swagger.InboundNatRule)
+}
+
+type resourceGatewayNatRulesInput struct {
+	GatewayId string
+	Rules     []swagger.InboundNatRule `json:"rule"`
+}
+
+func (rt _resourceGatewayNatRules) resource(swaggerSchema map[string]*schema.Schema) *schema.Resource {
+	return &schema.Resource{
+		CreateContext: rt.resourceGatewayNatRulesUpdate,
+		ReadContext:   rt.resourceGatewayNatRulesRead,
+		UpdateContext: rt.resourceGatewayNatRulesUpdate,
+		DeleteContext: rt.resourceGatewayNatRulesDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateComposite("gateway_id"),
+		},
+		CustomizeDiff: customdiff.All(
+			uniqueKeys("rule", "name"),
+			uniqueKeys("rule", "up_link_if_name", "public_ip", "public_port"),
+		),
+		Schema: swaggerSchema,
+	}
+}
+
+func resourceGatewayNatRules() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(resourceGatewayNatRulesInput{}, Cfg{
+		"gateway_id":           {Schema: schema.Schema{Required: true, ForceNew: true}},
+		"rule":                 {Schema: schema.Schema{Optional: true}},
+		"rule.name":            {Schema: schema.Schema{Required: true}},
+		"rule.public_ip":       {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4},
+		"rule.up_link_if_name": {Schema: schema.Schema{Required: true}},
+		"rule.lan_ip":          {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4},
+		"rule.bi_directional":  {Schema: schema.Schema{Required: true}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rt := _resourceGatewayNatRules{
+		Binder:      binder,
+		InputBinder: inputBinder,
+		Key:         "one2OneNatRules",
+		GetRules: func(gateway *swagger.Edge) []swagger.InboundNatRule {
+			return gateway.One2OneNatRules
+		},
+		SetRules: func(gateway *swagger.Edge, rules []swagger.InboundNatRule) {
+			gateway.One2OneNatRules = rules
+		},
+	}
+
+	return rt.resource(swaggerSchema), nil
+}
+
+func resourceGatewayPortForwardRules() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(resourceGatewayNatRulesInput{}, Cfg{
+		"gateway_id":           {Schema: schema.Schema{Required: true, ForceNew: true}},
+		"rule":                 {Schema: schema.Schema{Optional: true}},
+		"rule.name":            {Schema: schema.Schema{Required: true}},
+		"rule.public_ip":       {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4},
+		"rule.up_link_if_name": {Schema: schema.Schema{Required: true}},
+		"rule.lan_ip":          {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4},
+		"rule.bi_directional":  {Schema: schema.Schema{Required: true}},
+		"rule.lan_port":        {Schema: schema.Schema{Required: true}, Validate: ValidatePort},
+		"rule.public_port":     {Schema: schema.Schema{Required: true}, Validate: ValidatePort},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rt := _resourceGatewayNatRules{
+		Binder:      binder,
+		InputBinder: inputBinder,
+		Key:         "portForwardingNatRules",
+		GetRules: func(gateway *swagger.Edge) []swagger.InboundNatRule {
+			return gateway.PortForwardingNatRules
+		},
+		SetRules: func(gateway *swagger.Edge, rules []swagger.InboundNatRule) {
+			gateway.PortForwardingNatRules = rules
+		},
+	}
+
+	return rt.resource(swaggerSchema), nil
+}
@@ -0,0 +1,141 @@
+package bwan
+
+import (
+	"context"
+	"testing"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testPortForward(name, publicIp string, publicPort int) map[string]interface{} {
+	return map[string]interface{}{
+		"name":            name,
+		"public_ip":       publicIp,
+		"public_port":     publicPort,
+		"up_link_if_name": "GE1",
+		"lan_ip":          "192.168.1.10",
+		"lan_port":        8080,
+		"bi_directional":  false,
+	}
+}
+
+func TestResourceGatewayPortForwardRules(t *testing.T) {
+	nat := swagger.InboundNatRule{Name: "web", PublicIp: "1.1.1.1", UpLinkIfName: "GE1", LanIp: "192.168.1.1"}
+	api, client := newFakeEdgeAPI(t, swagger.Edge{
+		Id:              "gw1",
+		One2OneNatRules: []swagger.InboundNatRule{nat},
+		PortForwardingNatRules: []swagger.InboundNatRule{
+			{Name: "manual", PublicIp: "1.1.1.2", PublicPort: 22, UpLinkIfName: "GE1", LanIp: "192.168.1.2", LanPort: 22},
+		},
+	})
+
+	r := mustResource(t, resourceGatewayPortForwardRules)
+	config := map[string]interface{}{
+		"gateway_id": "gw1",
+		"rule": []interface{}{
+			testPortForward("https", "1.1.1.2", 443),
+			testPortForward("http", "1.1.1.2", 80),
+		},
+	}
+	d := schema.TestResourceDataRaw(t, r.Schema, config)
+
+	// The rule added elsewhere is replaced along with the rest.
+	diags := r.CreateContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, "gw1", d.Id())
+	assert.Equal(t, 1, api.count("PUT"))
+	rules := api.edge("gw1").PortForwardingNatRules
+	require.Len(t, rules, 2)
+	assert.Equal(t, "https", rules[0].Name)
+	assert.EqualValues(t, 443, rules[0].PublicPort)
+	assert.Equal(t, "http", rules[1].Name)
+	// The 1:1 NAT rules are left alone.
+	assert.Equal(t, []swagger.InboundNatRule{nat}, api.edge("gw1").One2OneNatRules)
+
+	// Rules added elsewhere are read, so they are planned for removal.
+	api.edges["gw1"].PortForwardingNatRules = append(api.edges["gw1"].PortForwardingNatRules,
+		swagger.InboundNatRule{Name: "manual", PublicIp: "1.1.1.2", PublicPort: 22, UpLinkIfName: "GE1", LanIp: "192.168.1.2", LanPort: 22})
+	diags = r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 3, d.Get("rule.#"))
+	assert.Equal(t, "manual", d.Get("rule.2.name"))
+
+	diff, err := r.SimpleDiff(context.Background(), d.State(), terraform.NewResourceConfigRaw(config), client)
+	require.NoError(t, err)
+	require.NotNil(t, diff)
+	assert.True(t, diff.Attributes["rule.2.name"].NewRemoved)
+
+	_, diags = r.Apply(context.Background(), d.State(), diff, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 2, api.count("PUT"))
+	assert.Equal(t, []string{"https", "http"}, itemKeys(api.edge("gw1").PortForwardingNatRules, natRuleKey))
+
+	diags = r.DeleteContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Empty(t, api.edge("gw1").PortForwardingNatRules)
+	assert.Equal(t, `{"portForwardingNatRules":[]}`, api.bodies[len(api.bodies)-1])
+	assert.Equal(t, []swagger.InboundNatRule{nat}, api.edge("gw1").One2OneNatRules)
+}
+
+func TestResourceGatewayNatRulesConflicts(t *testing.T) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"})
+
+	r := mustResource(t, resourceGatewayPortForwardRules)
+	_, err := r.SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"gateway_id": "gw1",
+		"rule": []interface{}{
+			testPortForward("web", "1.1.1.2", 443),
+			testPortForward("web", "1.1.1.2", 80),
+		},
+	}), client)
+	assert.ErrorContains(t, err, `rule blocks must have a unique name, "web" is used more than once`)
+
+	_, err = r.SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"gateway_id": "gw1",
+		"rule": []interface{}{
+			testPortForward("https", "1.1.1.2", 443),
+			testPortForward("https-alt", "1.1.1.2", 443),
+		},
+	}), client)
+	assert.ErrorContains(t, err, "must have a unique up_link_if_name/public_ip/public_port")
+
+	// The same public address and port on another uplink is fine.
+	other := testPortForward("https-alt", "1.1.1.2", 443)
+	other["up_link_if_name"] = "GE2"
+	_, err = r.SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"gateway_id": "gw1",
+		"rule": []interface{}{
+			testPortForward("https", "1.1.1.2", 443),
+			other,
+		},
+	}), client)
+	require.NoError(t, err)
+
+	// The per-rule resources cannot create a rule the gateway has.
+	api.edges["gw1"].PortForwardingNatRules = []swagger.InboundNatRule{
+		{Name: "ssh", PublicIp: "1.1.1.3", PublicPort: 22, UpLinkIfName: "GE1", LanIp: "192.168.1.10", LanPort: 22},
+	}
+	_, err = mustResource(t, resourceGatewayPortForward).SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(
+		testPortForwardConfig("gw1"),
+	), client)
+	assert.ErrorContains(t, err, `port forwarding rule "ssh" already exists on gateway gw1: import it, or declare it in `+
+		`netskopebwan_gateway_port_forward_rules if that resource manages the port forwarding rules of the gateway`)
+
+	_, err = mustResource(t, resourceGatewayNat).SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(
+		testPortForwardConfig("gw1"),
+	), client)
+	assert.NoError(t, err)
+}
+
+func testPortForwardConfig(gatewayId string) map[string]interface{} {
+	m := testPortForward("ssh", "1.1.1.3", 22)
+	m["gateway_id"] = gatewayId
+	return m
+}
@@ -0,0 +1,454 @@
+package bwan
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func (rt _resourceGatewayRollout) resourceGatewayRolloutApply(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	apiSvc := m.(*apiClient)
+	rolloutInput, err := ApplyBinderInputResourceData[resourceGatewayRolloutInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.Id() == "" {
+		d.SetId(utils.Hash(rolloutInput.GatewayIds))
+	}
+
+	result, err := rolloutInput.run(ctx, apiSvc)
+	if err != nil {
+		diags = edgeWriteError(err, rt.Binder)
+	}
+	diags = append(diags, result.diags(rolloutInput)...)
+
+	rolloutInput.AppliedGatewayIds = result.applied
+	rolloutInput.FailedGatewayIds = result.failedIds()
+	if err := ApplyBinderResourceData(rt.Binder, d, rolloutInput); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	return diags
+}
+
+func (rt _resourceGatewayRollout) resourceGatewayRolloutRead(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	apiSvc := m.(*apiClient)
+	rolloutInput, err := ApplyBinderInputResourceData[resourceGatewayRolloutInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// A gateway that is gone no longer carries the change, but the rollout
+	// is kept for the others.
+	rolloutInput.AppliedGatewayIds = []string{}
+	for _, id := range rolloutInput.GatewayIds {
+		gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, id, nil)
+		if err != nil {
+			if apiSvc.isNotFound(err, resp, "/edges/"+id) {
+				continue
+			}
+			return apiError("GetEdgeById", resp, err, rt.Binder)
+		}
+		if rolloutInput.carries(gateway) {
+			rolloutInput.AppliedGatewayIds = append(rolloutInput.AppliedGatewayIds, id)
+		}
+	}
+	rolloutInput.FailedGatewayIds = toStrings(d.Get("failed_gateway_ids").([]interface{}))
+
+	err = ApplyBinderResourceData(rt.Binder, d, rolloutInput)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func (rt _resourceGatewayRollout) resourceGatewayRolloutDelete(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// The gateways keep the change.
+	d.SetId("")
+	return diags
+}
+
+// planRollout plans a rollout while a gateway does not carry the change,
+// e.g. after a rollout stopped on failures or a gateway was changed
+// elsewhere, so that applying again resumes it.
+func planRollout(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	applied := toStrings(d.Get("applied_gateway_ids").([]interface{}))
+	ids := toStrings(d.Get("gateway_ids").([]interface{}))
+	pending := slices.ContainsFunc(ids, func(id string) bool {
+		return !slices.Contains(applied, id)
+	})
+	if !pending && !d.HasChanges("gateway_ids", "assigned_policy", "software_version") {
+		return nil
+	}
+
+	if err := d.SetNewComputed("applied_gateway_ids"); err != nil {
+		return err
+	}
+	return d.SetNewComputed("failed_gateway_ids")
+}
+
+// rolloutFailure is a gateway that did not take the change, or was not
+// healthy after it.
+type rolloutFailure struct {
+	id  string
+	err error
+}
+
+type rolloutResult struct {
+	// applied are the gateways carrying the change, in the order of
+	// gateway_ids, including those failing the health check.
+	applied  []string
+	failures []rolloutFailure
+	// skipped are the gateways left unchanged when the rollout stopped.
+	skipped []string
+}
+
+func (r rolloutResult) failedIds() []string {
+	ids := []string{}
+	for _, f := range r.failures {
+		ids = append(ids, f.id)
+	}
+
+	return ids
+}
+
+// diags reports the failures of the rollout, as an error once there are
+// more than max_failures of them.
+func (r rolloutResult) diags(in resourceGatewayRolloutInput) diag.Diagnostics {
+	if len(r.failures) == 0 {
+		return nil
+	}
+
+	var detail strings.Builder
+	for _, f := range r.failures {
+		fmt.Fprintf(&detail, "%s: %s\n", f.id, f.err)
+	}
+
+	if len(r.failures) <= in.MaxFailures {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Gateway rollout completed with %d failed gateways", len(r.failures)),
+			Detail:   detail.String(),
+		}}
+	}
+
+	if len(r.skipped) > 0 {
+		fmt.Fprintf(&detail, "\nNot changed: %s\n", strings.Join(r.skipped, ", "))
+	}
+	detail.WriteString("\nApplying again resumes the rollout with the gateways not carrying the change.")
+
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary: fmt.Sprintf("Gateway rollout stopped after %d failed gateways, more than max_failures %d",
+			len(r.failures), in.MaxFailures),
+		Detail: detail.String(),
+	}}
+}
+
+// run applies the change to the gateways not carrying it yet, batch_size
+// at a time, checking the health of each wave before the next. It stops
+// once more than max_failures gateways failed. The error is set only if
+// the rollout was cut short, e.g. by its timeout.
+func (in resourceGatewayRolloutInput) run(ctx context.Context, apiSvc *apiClient) (result rolloutResult, err error) {
+	applied := map[string]bool{}
+	defer func() {
+		for _, id := range in.GatewayIds {
+			if applied[id] {
+				result.applied = append(result.applied, id)
+			}
+		}
+	}()
+
+	var pending []string
+	for _, id := range in.GatewayIds {
+		gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(withFreshEdge(ctx), id, nil)
+		switch {
+		case ctx.Err() != nil:
+			return result, fmt.Errorf("gateway rollout cut short: %w", ctx.Err())
+		case err != nil:
+			result.failures = append(result.failures, rolloutFailure{id, edgeAPIError{op: "GetEdgeById", resp: resp, err: err}})
+		case in.carries(gateway):
+			applied[id] = true
+		default:
+			pending = append(pending, id)
+		}
+	}
+
+	waves := slices.Collect(slices.Chunk(pending, in.BatchSize))
+	for n, wave := range waves {
+		if len(result.failures) > in.MaxFailures {
+			result.skipped = slices.Concat(waves[n:]...)
+			break
+		}
+
+		since := time.Now()
+		var written []string
+		for _, id := range wave {
+			err := updateEdgeFields(ctx, apiSvc, id, in.change())
+			switch {
+			case ctx.Err() != nil:
+				return result, fmt.Errorf("gateway rollout cut short: %w", ctx.Err())
+			case err != nil:
+				result.failures = append(result.failures, rolloutFailure{id, err})
+			default:
+				applied[id] = true
+				written = append(written, id)
+			}
+		}
+
+		failures, err := in.checkHealth(ctx, apiSvc, n+1, written, since)
+		result.failures = append(result.failures, failures...)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// checkHealth waits up to health_timeout for the gateways of the wave to be
+// healthy, returning those that are not. The error is set only if ctx is
+// done first.
+func (in resourceGatewayRolloutInput) checkHealth(
+	ctx context.Context, apiSvc *apiClient, wave int, ids []string, since time.Time) ([]rolloutFailure, error) {
+	if in.HealthCheck == "none" || len(ids) == 0 {
+		return nil, nil
+	}
+
+	// The duration was validated with the config.
+	timeout, _ := time.ParseDuration(in.HealthTimeout)
+	waveCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var failures []rolloutFailure
+	pending := slices.Clone(ids)
+	reasons := map[string]string{}
+	err := poll(waveCtx, fmt.Sprintf("wave %d to be healthy", wave), func(ctx context.Context) (bool, error) {
+		var unhealthy []string
+		for _, id := range pending {
+			reason, err := in.unhealthy(ctx, apiSvc, id, since)
+			switch {
+			case ctx.Err() != nil:
+				return false, nil
+			case err != nil:
+				failures = append(failures, rolloutFailure{id, err})
+			case reason != "":
+				reasons[id] = reason
+				unhealthy = append(unhealthy, id)
+			}
+		}
+		pending = unhealthy
+
+		return len(pending) == 0, nil
+	})
+	if err == nil {
+		return failures, nil
+	}
+	if ctx.Err() != nil {
+		return failures, err
+	}
+
+	for _, id := range pending {
+		reason := reasons[id]
+		if reason == "" {
+			reason = "not checked"
+		}
+		failures = append(failures, rolloutFailure{id, fmt.Errorf("not healthy after %s: %s", timeout, reason)})
+	}
+
+	return failures, nil
+}
+
+// unhealthy returns why the gateway is not healthy after the change made
+// at since, or "" if it is.
+func (in resourceGatewayRolloutInput) unhealthy(
+	ctx context.Context, apiSvc *apiClient, id string, since time.Time) (string, error) {
+	gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(withFreshEdge(ctx), id, nil)
+	if err != nil {
+		return "", edgeAPIError{op: "GetEdgeById", resp: resp, err: err}
+	}
+	if !gateway.Activated {
+		return "not activated", nil
+	}
+	if in.HealthCheck == "activated" {
+		return "", nil
+	}
+
+	status, err := lastStatus(ctx, apiSvc, id)
+	if err != nil {
+		return "", err
+	}
+	if status.Status == nil || status.Status.DsDevstatusGeneral == nil || !status.TimePublished.After(since) {
+		return "no status reported since the change", nil
+	}
+	if reported := reportedVersion(status); in.SoftwareVersion != "" && reported != in.SoftwareVersion {
+		return fmt.Sprintf("reports version %s", reported), nil
+	}
+
+	return "", nil
+}
+
+// carries reports whether the gateway has the change already.
+func (in resourceGatewayRolloutInput) carries(gateway swagger.Edge) bool {
+	if in.AssignedPolicy != "" && (gateway.AssignedPolicy == nil || gateway.AssignedPolicy.Id != in.AssignedPolicy) {
+		return false
+	}
+	if in.SoftwareVersion != "" && gateway.Swversion != in.SoftwareVersion {
+		return false
+	}
+
+	return true
+}
+
+// change is the update applying the change to a gateway.
+func (in resourceGatewayRolloutInput) change() swagger.UpdateEdgeInput {
+	input := swagger.UpdateEdgeInput{Swversion: in.SoftwareVersion}
+	if in.AssignedPolicy != "" {
+		input.AssignedPolicy = &swagger.PolicyRef{Id: in.AssignedPolicy}
+	}
+
+	return input
+}
+
+func toStrings(list []interface{}) []string {
+	s := make([]string, len(list))
+	for i, v := range list {
+		s[i], _ = v.(string)
+	}
+
+	return s
+}
+
+type _resourceGatewayRollout struct {
+	Binder      []FieldBinder
+	InputBinder []FieldBinder
+}
+
+type resourceGatewayRolloutInput struct {
+	GatewayIds        []string
+	AssignedPolicy    string
+	SoftwareVersion   string
+	BatchSize         int
+	MaxFailures       int
+	HealthCheck       string
+	HealthTimeout     string
+	AppliedGatewayIds []string
+	FailedGatewayIds  []string
+}
+
+func resourceGatewayRollout() (*schema.Resource, error) {
+	change := []string{"assigned_policy", "software_version"}
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(resourceGatewayRolloutInput{}, Cfg{
+		"gateway_ids": {
+			Schema: schema.Schema{
+				Required:    true,
+				MinItems:    1,
+				Description: "Gateways to change, in the order of the rollout.",
+			},
+		},
+		"assigned_policy": {
+			Schema: schema.Schema{
+				Optional:     true,
+				AtLeastOneOf: change,
+				Description:  "ID of the policy to assign to the gateways.",
+			},
+		},
+		"software_version": {
+			Schema: schema.Schema{
+				Optional:     true,
+				AtLeastOneOf: change,
+				Description:  "Software version to assign to the gateways.",
+			},
+		},
+		"batch_size": {
+			Schema: schema.Schema{
+				Optional:    true,
+				Default:     10,
+				Description: "Number of gateways changed per wave. Defaults to `10`.",
+			},
+			Validate: validation.IntAtLeast(1),
+		},
+		"max_failures": {
+			Schema: schema.Schema{
+				Optional: true,
+				Default:  0,
+				Description: "Number of gateways that may fail to take the change or the health check. " +
+					"The rollout stops before the next wave once there are more. Defaults to `0`.",
+			},
+			Validate: validation.IntAtLeast(0),
+		},
+		"health_check": {
+			Schema: schema.Schema{
+				Optional: true,
+				Default:  "online",
+				Description: "Check between waves that each changed gateway is still `activated`, or also `online`, " +
+					"reporting a status since the change and, if `software_version` is set, running it. " +
+					"`none` skips the check. Defaults to `online`.",
+			},
+			Validate: ValidateOneOf("none", "activated", "online"),
+		},
+		"health_timeout": {
+			Schema: schema.Schema{
+				Optional:    true,
+				Default:     "15m",
+				Description: "How long to wait for the gateways of a wave to be healthy, as a duration. Defaults to `15m`.",
+			},
+			Validate: ValidateDuration,
+		},
+		"applied_gateway_ids": {
+			Schema: schema.Schema{
+				Computed: true,
+				Description: "Gateways carrying the change, as last read from the orchestrator. A rollout is planned " +
+					"while any of `gateway_ids` is missing, resuming a stopped one.",
+			},
+		},
+		"failed_gateway_ids": {
+			Schema: schema.Schema{
+				Computed:    true,
+				Description: "Gateways that failed to take the change or the health check in the last rollout.",
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rt := _resourceGatewayRollout{Binder: binder, InputBinder: inputBinder}
+
+	return &schema.Resource{
+		CreateContext: rt.resourceGatewayRolloutApply,
+		ReadContext:   rt.resourceGatewayRolloutRead,
+		UpdateContext: rt.resourceGatewayRolloutApply,
+		DeleteContext: rt.resourceGatewayRolloutDelete,
+		CustomizeDiff: planRollout,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(2 * time.Hour),
+			Update: schema.DefaultTimeout(2 * time.Hour),
+		},
+		Schema: swaggerSchema,
+	}, nil
+}
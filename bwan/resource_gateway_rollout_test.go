@@ -0,0 +1,322 @@
+package bwan
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeFleet serves n activated gateways gw1 to gwN on version 4.1.0
+// and policy p1. Their devices report a status with the version assigned
+// to them whenever polled, except for the broken ones, which stay on 4.1.0.
+func newFakeFleet(t *testing.T, n int, broken ...string) (*fakeEdgeAPI, *apiClient) {
+	var edges []swagger.Edge
+	for i := 1; i <= n; i++ {
+		edges = append(edges, swagger.Edge{
+			Id:             fmt.Sprintf("gw%d", i),
+			Activated:      true,
+			Swversion:      "4.1.0",
+			AssignedPolicy: &swagger.PolicyRef{Id: "p1"},
+		})
+	}
+
+	api, client := newFakeEdgeAPI(t, edges...)
+	api.afterGet = func(edge *swagger.Edge) {
+		if slices.Contains(broken, edge.Id) {
+			api.statuses[edge.Id] = softwareStatus("4.1.0")
+		} else {
+			api.statuses[edge.Id] = softwareStatus(edge.Swversion)
+		}
+	}
+
+	return api, client
+}
+
+func rollout(t *testing.T, client *apiClient, raw map[string]interface{}) (*schema.ResourceData, diag.Diagnostics) {
+	r := mustResource(t, resourceGatewayRollout)
+	d := schema.TestResourceDataRaw(t, r.Schema, raw)
+
+	return d, r.CreateContext(context.Background(), d, client)
+}
+
+// puts returns the IDs of the gateways written, in order.
+func puts(api *fakeEdgeAPI) []string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	var ids []string
+	for _, r := range api.requests {
+		if id, ok := strings.CutPrefix(r, "PUT /edges/"); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func TestGatewayRolloutWaves(t *testing.T) {
+	fastPolls(t)
+	api, client := newFakeFleet(t, 5)
+
+	d, diags := rollout(t, client, map[string]interface{}{
+		"gateway_ids":     []interface{}{"gw5", "gw1", "gw2", "gw3", "gw4"},
+		"assigned_policy": "p2",
+		"batch_size":      2,
+	})
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Empty(t, diags)
+
+	assert.Equal(t, []string{"gw5", "gw1", "gw2", "gw3", "gw4"}, puts(api))
+	for _, id := range puts(api) {
+		assert.Equal(t, "p2", api.edge(id).AssignedPolicy.Id)
+		assert.Equal(t, "4.1.0", api.edge(id).Swversion)
+	}
+	assert.Equal(t, []interface{}{"gw5", "gw1", "gw2", "gw3", "gw4"}, d.Get("applied_gateway_ids"))
+	assert.Empty(t, d.Get("failed_gateway_ids"))
+
+	// Each wave is checked before the next one starts.
+	api.mu.Lock()
+	requests := slices.Clone(api.requests)
+	api.mu.Unlock()
+	second := slices.Index(requests, "PUT /edges/gw2")
+	require.Positive(t, second)
+	for _, id := range []string{"gw5", "gw1"} {
+		assert.Contains(t, requests[:second], "GET /edges/"+id+"/status")
+	}
+	assert.NotContains(t, requests[:second], "GET /edges/gw2/status")
+}
+
+func TestGatewayRolloutStopsOnFailures(t *testing.T) {
+	fastPolls(t)
+	api, client := newFakeFleet(t, 6, "gw2")
+
+	d, diags := rollout(t, client, map[string]interface{}{
+		"gateway_ids":      []interface{}{"gw1", "gw2", "gw3", "gw4", "gw5", "gw6"},
+		"software_version": "4.2.0",
+		"batch_size":       2,
+		"health_timeout":   "50ms",
+	})
+	require.True(t, diags.HasError())
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Gateway rollout stopped after 1 failed gateways, more than max_failures 0", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "gw2: not healthy after 50ms: reports version 4.1.0\n")
+	assert.Contains(t, diags[0].Detail, "Not changed: gw3, gw4, gw5, gw6\n")
+
+	assert.Equal(t, []string{"gw1", "gw2"}, puts(api))
+	assert.Equal(t, "4.1.0", api.edge("gw3").Swversion)
+	assert.Equal(t, []interface{}{"gw1", "gw2"}, d.Get("applied_gateway_ids"))
+	assert.Equal(t, []interface{}{"gw2"}, d.Get("failed_gateway_ids"))
+}
+
+func TestGatewayRolloutToleratesFailures(t *testing.T) {
+	fastPolls(t)
+	api, client := newFakeFleet(t, 4, "gw2")
+	delete(api.edges, "gw3")
+
+	d, diags := rollout(t, client, map[string]interface{}{
+		"gateway_ids":      []interface{}{"gw1", "gw2", "gw3", "gw4"},
+		"software_version": "4.2.0",
+		"batch_size":       1,
+		"max_failures":     2,
+		"health_timeout":   "50ms",
+	})
+	require.False(t, diags.HasError(), "%v", diags)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "Gateway rollout completed with 2 failed gateways", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "gw3: GetEdgeById: 404 Not Found\n")
+
+	assert.Equal(t, []string{"gw1", "gw2", "gw4"}, puts(api))
+	assert.Equal(t, []interface{}{"gw1", "gw2", "gw4"}, d.Get("applied_gateway_ids"))
+	assert.Equal(t, []interface{}{"gw3", "gw2"}, d.Get("failed_gateway_ids"))
+}
+
+func TestGatewayRolloutHealthChecks(t *testing.T) {
+	fastPolls(t)
+
+	tests := []struct {
+		check  string
+		failed []interface{}
+	}{
+		// The device of gw1 is activated but no longer reports its status.
+		{"online", []interface{}{"gw1"}},
+		{"activated", []interface{}{}},
+		{"none", []interface{}{}},
+	}
+	for _, test := range tests {
+		t.Run(test.check, func(t *testing.T) {
+			api, client := newFakeFleet(t, 2)
+			api.afterGet = func(edge *swagger.Edge) {
+				if edge.Id != "gw1" {
+					api.statuses[edge.Id] = softwareStatus(edge.Swversion)
+				}
+			}
+
+			d, diags := rollout(t, client, map[string]interface{}{
+				"gateway_ids":     []interface{}{"gw1", "gw2"},
+				"assigned_policy": "p2",
+				"max_failures":    1,
+				"health_check":    test.check,
+				"health_timeout":  "50ms",
+			})
+			require.False(t, diags.HasError(), "%v", diags)
+			assert.Equal(t, test.failed, d.Get("failed_gateway_ids"))
+			if test.check == "none" {
+				// Only the gateways are read before the change.
+				assert.Equal(t, 2, api.count("GET"))
+			}
+		})
+	}
+}
+
+func TestGatewayRolloutResume(t *testing.T) {
+	fastPolls(t)
+	api, client := newFakeFleet(t, 3, "gw1")
+	r := mustResource(t, resourceGatewayRollout)
+	raw := map[string]interface{}{
+		"gateway_ids":      []interface{}{"gw1", "gw2", "gw3"},
+		"software_version": "4.2.0",
+		"batch_size":       1,
+		"health_timeout":   "50ms",
+	}
+	d, diags := rollout(t, client, raw)
+	require.True(t, diags.HasError())
+	assert.Equal(t, []string{"gw1"}, puts(api))
+
+	diff := func(state *terraform.InstanceState) *terraform.InstanceDiff {
+		diff, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), client)
+		require.NoError(t, err)
+		if diff == nil {
+			return &terraform.InstanceDiff{}
+		}
+		return diff
+	}
+
+	// The stopped rollout is planned again, continuing with the gateways
+	// not carrying the change.
+	d = r.Data(d.State())
+	diags = r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, []interface{}{"gw1"}, d.Get("applied_gateway_ids"))
+	assert.True(t, diff(d.State()).Attributes["applied_gateway_ids.#"].NewComputed)
+
+	api.afterGet = func(edge *swagger.Edge) {
+		api.statuses[edge.Id] = softwareStatus(edge.Swversion)
+	}
+	diags = r.UpdateContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, []string{"gw1", "gw2", "gw3"}, puts(api))
+	assert.Equal(t, []interface{}{"gw1", "gw2", "gw3"}, d.Get("applied_gateway_ids"))
+	assert.Empty(t, d.Get("failed_gateway_ids"))
+
+	// Once done, it is only planned again when a gateway drifts.
+	diags = r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Empty(t, diff(d.State()).Attributes)
+
+	api.edges["gw3"].Swversion = "4.1.0"
+	diags = r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, []interface{}{"gw1", "gw2"}, d.Get("applied_gateway_ids"))
+	assert.True(t, diff(d.State()).Attributes["applied_gateway_ids.#"].NewComputed)
+}
+
+func TestGatewayUpdateAfterRollout(t *testing.T) {
+	fastPolls(t)
+	api, client := newFakeFleet(t, 1)
+	r := mustResource(t, resourceGateway)
+	config := func(name, policy string) *terraform.ResourceConfig {
+		return terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":            name,
+			"assigned_policy": []interface{}{map[string]interface{}{"id": policy}},
+		})
+	}
+	update := func(state *terraform.InstanceState, name, policy string) *terraform.InstanceState {
+		diff, err := r.SimpleDiff(context.Background(), state, config(name, policy), client)
+		require.NoError(t, err)
+		state, diags := r.Apply(context.Background(), state, diff, client)
+		require.False(t, diags.HasError(), "%v", diags)
+		return state
+	}
+
+	// The gateway was created with policy p1, which a rollout then changed
+	// without the gateway being refreshed.
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":            "gw",
+		"assigned_policy": []interface{}{map[string]interface{}{"id": "p1"}},
+	})
+	d.SetId("gw1")
+	_, diags := rollout(t, client, map[string]interface{}{
+		"gateway_ids":     []interface{}{"gw1"},
+		"assigned_policy": "p2",
+	})
+	require.False(t, diags.HasError(), "%v", diags)
+	require.Equal(t, "p2", api.edge("gw1").AssignedPolicy.Id)
+
+	state := update(d.State(), "renamed", "p1")
+	assert.Equal(t, "renamed", api.edge("gw1").Name)
+	assert.Equal(t, "p2", api.edge("gw1").AssignedPolicy.Id)
+	assert.NotContains(t, api.bodies[len(api.bodies)-1], "assignedPolicy")
+
+	// Changing the policy of the gateway itself still assigns it.
+	update(state, "renamed", "p3")
+	assert.Equal(t, "p3", api.edge("gw1").AssignedPolicy.Id)
+}
+
+func TestGatewayRefreshAfterRollout(t *testing.T) {
+	fastPolls(t)
+	api, client := newFakeFleet(t, 1)
+	r := mustResource(t, resourceGateway)
+	refresh := func(state *terraform.InstanceState) *terraform.InstanceState {
+		state, diags := r.RefreshWithoutUpgrade(context.Background(), state, client)
+		require.False(t, diags.HasError(), "%v", diags)
+		return state
+	}
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"name": "gw"})
+	d.SetId("gw1")
+	state := refresh(d.State())
+	require.Equal(t, "4.1.0", state.Attributes["swversion"])
+
+	_, diags := rollout(t, client, map[string]interface{}{
+		"gateway_ids":      []interface{}{"gw1"},
+		"assigned_policy":  "p2",
+		"software_version": "4.2.0",
+	})
+	require.False(t, diags.HasError(), "%v", diags)
+	state = refresh(state)
+	assert.Equal(t, "4.2.0", state.Attributes["swversion"])
+
+	// A configured software version differs from the refreshed one and is
+	// planned back, which is why the docs ask to leave it unset.
+	diff, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":      "gw",
+		"swversion": "4.1.0",
+	}), client)
+	require.NoError(t, err)
+	require.NotNil(t, diff.Attributes["swversion"])
+	assert.Equal(t, "4.1.0", diff.Attributes["swversion"].New)
+
+	// Left unset, a rename keeps what the rollout assigned.
+	diff, err = r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name": "renamed",
+	}), client)
+	require.NoError(t, err)
+	assert.Nil(t, diff.Attributes["swversion"])
+	assert.Nil(t, diff.Attributes["assigned_policy.#"])
+	state, diags = r.Apply(context.Background(), state, diff, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, "renamed", api.edge("gw1").Name)
+	assert.Equal(t, "p2", api.edge("gw1").AssignedPolicy.Id)
+	assert.Equal(t, "4.2.0", api.edge("gw1").Swversion)
+	assert.Equal(t, "4.2.0", state.Attributes["swversion"])
+}
@@ -0,0 +1,240 @@
+package bwan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// softwareRollbackTimeout bounds the request restoring the prior version
+// after a failed upgrade, which runs after the timeout of the upgrade has
+// expired.
+const softwareRollbackTimeout = time.Minute
+
+func (rt _resourceGatewaySoftware) resourceGatewaySoftwareUpdate(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	apiSvc := m.(*apiClient)
+	softwareInput, err := ApplyBinderInputResourceData[resourceGatewaySoftwareInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(withFreshEdge(ctx), softwareInput.GatewayId, nil)
+	if err != nil {
+		return apiError("GetEdgeById", resp, err, rt.Binder)
+	}
+
+	// The version the gateway was assigned before this resource first
+	// changed it, kept across later upgrades.
+	previous := d.Get("previous_version").(string)
+	if previous == "" {
+		previous = gateway.Swversion
+	}
+	rollbackTo := gateway.Swversion
+
+	if gateway.Swversion != softwareInput.Version {
+		err = updateEdgeFields(ctx, apiSvc, softwareInput.GatewayId, swagger.UpdateEdgeInput{Swversion: softwareInput.Version})
+		if err != nil {
+			return edgeWriteError(err, rt.Binder)
+		}
+	}
+
+	d.SetId(softwareInput.GatewayId)
+	softwareInput.PreviousVersion = previous
+
+	if softwareInput.Wait {
+		err = waitSoftwareVersion(ctx, apiSvc, softwareInput.GatewayId, softwareInput.Version)
+		if err != nil {
+			diags = edgeWriteError(err, rt.Binder)
+			if softwareInput.Rollback && rollbackTo != "" && rollbackTo != softwareInput.Version {
+				diags = append(diags, rollbackSoftware(ctx, apiSvc, softwareInput.GatewayId, rollbackTo))
+			}
+			return diags
+		}
+		softwareInput.ReportedVersion = softwareInput.Version
+	}
+
+	err = ApplyBinderResourceData(rt.Binder, d, softwareInput)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// rollbackSoftware assigns the gateway its prior version again after a
+// failed upgrade, reporting the outcome. It is attempted even if ctx has
+// expired, as it is the timeout that failed the upgrade.
+func rollbackSoftware(ctx context.Context, apiSvc *apiClient, id, version string) diag.Diagnostic {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), softwareRollbackTimeout)
+	defer cancel()
+
+	if err := updateEdgeFields(ctx, apiSvc, id, swagger.UpdateEdgeInput{Swversion: version}); err != nil {
+		return diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Rolling gateway %s back to version %s failed", id, version),
+			Detail:   err.Error(),
+		}
+	}
+
+	return diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Gateway %s was rolled back to version %s", id, version),
+		Detail: "The orchestrator accepted the prior version. Whether the device can downgrade to it " +
+			"depends on the versions involved.",
+	}
+}
+
+func (rt _resourceGatewaySoftware) resourceGatewaySoftwareRead(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	apiSvc := m.(*apiClient)
+
+	gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, d.Id(), nil)
+	if err != nil {
+		if apiSvc.isNotFound(err, resp, "/edges/"+d.Id()) {
+			d.SetId("")
+			return diags
+		}
+		return apiError("GetEdgeById", resp, err, rt.Binder)
+	}
+
+	status, err := lastStatus(ctx, apiSvc, d.Id())
+	if err != nil {
+		return edgeWriteError(err, rt.Binder)
+	}
+
+	softwareInput, err := ApplyBinderInputResourceData[resourceGatewaySoftwareInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	softwareInput.GatewayId = gateway.Id
+	softwareInput.Version = gateway.Swversion
+	softwareInput.PreviousVersion = d.Get("previous_version").(string)
+	softwareInput.ReportedVersion = reportedVersion(status)
+
+	err = ApplyBinderResourceData(rt.Binder, d, softwareInput)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func (rt _resourceGatewaySoftware) resourceGatewaySoftwareDelete(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// The gateway stays on its version, there is nothing to go back to.
+	d.SetId("")
+	return diags
+}
+
+// reportedVersion returns the software version the device last reported.
+func reportedVersion(status swagger.EdgeStatusRef) string {
+	if status.Status == nil || status.Status.DsDevstatusGeneral == nil {
+		return ""
+	}
+
+	return status.Status.DsDevstatusGeneral.DsgSwversion
+}
+
+// waitSoftwareVersion waits until the device of the gateway reports running
+// the version.
+func waitSoftwareVersion(ctx context.Context, apiSvc *apiClient, id, version string) error {
+	var reported string
+	err := poll(ctx, fmt.Sprintf("gateway %s to run version %s", id, version), func(ctx context.Context) (bool, error) {
+		status, err := lastStatus(ctx, apiSvc, id)
+		if err != nil {
+			return false, err
+		}
+
+		reported = reportedVersion(status)
+		return reported == version, nil
+	})
+	if err != nil && errors.Is(err, context.DeadlineExceeded) && reported != "" {
+		return fmt.Errorf("%w, the device last reported version %s", err, reported)
+	}
+
+	return err
+}
+
+type _resourceGatewaySoftware struct {
+	Binder      []FieldBinder
+	InputBinder []FieldBinder
+}
+
+type resourceGatewaySoftwareInput struct {
+	GatewayId       string
+	Version         string
+	Wait            bool
+	Rollback        bool
+	PreviousVersion string
+	ReportedVersion string
+}
+
+func resourceGatewaySoftware() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(resourceGatewaySoftwareInput{}, Cfg{
+		"gateway_id": {Schema: schema.Schema{Required: true, ForceNew: true}},
+		"version": {
+			Schema: schema.Schema{
+				Required:    true,
+				Description: "Software version assigned to the gateway, e.g. one of the `versions` of `netskopebwan_software_versions`.",
+			},
+		},
+		"wait": {
+			Schema: schema.Schema{
+				Optional:    true,
+				Description: "Wait until the device reports running `version`.",
+			},
+		},
+		"rollback": {
+			Schema: schema.Schema{
+				Optional: true,
+				Description: "If waiting for the version fails, e.g. on timeout, assign the gateway its prior " +
+					"version again. Whether the device can downgrade depends on the versions involved.",
+			},
+		},
+		"previous_version": {
+			Schema: schema.Schema{
+				Computed:    true,
+				Description: "Software version of the gateway before it was first managed by this resource.",
+			},
+		},
+		"reported_version": {
+			Schema: schema.Schema{
+				Computed:    true,
+				Description: "Software version last reported by the device.",
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rt := _resourceGatewaySoftware{Binder: binder, InputBinder: inputBinder}
+
+	return &schema.Resource{
+		CreateContext: rt.resourceGatewaySoftwareUpdate,
+		ReadContext:   rt.resourceGatewaySoftwareRead,
+		UpdateContext: rt.resourceGatewaySoftwareUpdate,
+		DeleteContext: rt.resourceGatewaySoftwareDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateComposite("gateway_id"),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Update: schema.DefaultTimeout(60 * time.Minute),
+		},
+		Schema: swaggerSchema,
+	}, nil
+}
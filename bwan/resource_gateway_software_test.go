@@ -0,0 +1,214 @@
+package bwan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeSoftwareAPI serves gw1 on version 4.1.0, whose device reports
+// the version assigned to it after upgradePolls polls of its status, or
+// never if upgradePolls is 0.
+func newFakeSoftwareAPI(t *testing.T, upgradePolls int) (*fakeEdgeAPI, *apiClient) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1", Swversion: "4.1.0"})
+	api.statuses["gw1"] = softwareStatus("4.1.0")
+	polls := 0
+	api.afterGet = func(edge *swagger.Edge) {
+		polls++
+		if upgradePolls > 0 && polls >= upgradePolls {
+			api.statuses["gw1"] = softwareStatus(edge.Swversion)
+		}
+	}
+
+	return api, client
+}
+
+func softwareStatus(version string) *swagger.EdgeStatusRef {
+	return &swagger.EdgeStatusRef{
+		TimePublished: time.Now(),
+		Status: &swagger.EdgeStatus{DsDevstatusGeneral: &swagger.EdgeStatusDsDevstatusGeneral{
+			DsgSwversion: version,
+		}},
+	}
+}
+
+func upgradeGateway(t *testing.T, ctx context.Context, client *apiClient, raw map[string]interface{}) (*schema.ResourceData, diag.Diagnostics) {
+	r := mustResource(t, resourceGatewaySoftware)
+	d := schema.TestResourceDataRaw(t, r.Schema, raw)
+
+	return d, r.CreateContext(ctx, d, client)
+}
+
+func TestGatewaySoftwareUpgrade(t *testing.T) {
+	fastPolls(t)
+	api, client := newFakeSoftwareAPI(t, 3)
+
+	d, diags := upgradeGateway(t, context.Background(), client, map[string]interface{}{
+		"gateway_id": "gw1",
+		"version":    "4.2.0",
+		"wait":       true,
+	})
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, "gw1", d.Id())
+	assert.Equal(t, "4.1.0", d.Get("previous_version"))
+	assert.Equal(t, "4.2.0", d.Get("reported_version"))
+	// Only the version is written, leaving the rest of the gateway alone.
+	assert.Equal(t, []string{`{"swversion":"4.2.0"}` + "\n"}, api.bodies)
+	assert.Equal(t, "4.2.0", api.edge("gw1").Swversion)
+}
+
+func TestGatewaySoftwareNoWait(t *testing.T) {
+	api, client := newFakeSoftwareAPI(t, 0)
+
+	d, diags := upgradeGateway(t, context.Background(), client, map[string]interface{}{
+		"gateway_id": "gw1",
+		"version":    "4.2.0",
+	})
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "4.1.0", d.Get("previous_version"))
+	assert.Equal(t, 1, api.count("PUT"))
+	assert.Equal(t, 1, api.count("GET"))
+
+	// The gateway is already on the version.
+	_, diags = upgradeGateway(t, context.Background(), client, map[string]interface{}{
+		"gateway_id": "gw1",
+		"version":    "4.2.0",
+	})
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 1, api.count("PUT"))
+}
+
+func TestGatewaySoftwareRollback(t *testing.T) {
+	for _, rollback := range []bool{false, true} {
+		t.Run(fmt.Sprintf("rollback=%t", rollback), func(t *testing.T) {
+			fastPolls(t)
+			api, client := newFakeSoftwareAPI(t, 0)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			_, diags := upgradeGateway(t, ctx, client, map[string]interface{}{
+				"gateway_id": "gw1",
+				"version":    "4.2.0",
+				"wait":       true,
+				"rollback":   rollback,
+			})
+			require.True(t, diags.HasError())
+			assert.Contains(t, diags[0].Summary, "gave up waiting for gateway gw1 to run version 4.2.0")
+			assert.Contains(t, diags[0].Summary, "the device last reported version 4.1.0")
+
+			if !rollback {
+				require.Len(t, diags, 1)
+				assert.Equal(t, "4.2.0", api.edge("gw1").Swversion)
+				return
+			}
+			require.Len(t, diags, 2)
+			assert.Equal(t, diag.Warning, diags[1].Severity)
+			assert.Equal(t, "Gateway gw1 was rolled back to version 4.1.0", diags[1].Summary)
+			assert.Equal(t, "4.1.0", api.edge("gw1").Swversion)
+		})
+	}
+}
+
+func TestGatewaySoftwareRead(t *testing.T) {
+	fastPolls(t)
+	api, client := newFakeSoftwareAPI(t, 1)
+	r := mustResource(t, resourceGatewaySoftware)
+	d, diags := upgradeGateway(t, context.Background(), client, map[string]interface{}{
+		"gateway_id": "gw1",
+		"version":    "4.2.0",
+		"wait":       true,
+	})
+	require.False(t, diags.HasError(), "%v", diags)
+
+	// The version was changed elsewhere, and the device is still upgrading.
+	api.afterGet = nil
+	api.edges["gw1"].Swversion = "4.3.0"
+	d = r.Data(d.State())
+	diags = r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "4.3.0", d.Get("version"))
+	assert.Equal(t, "4.1.0", d.Get("previous_version"))
+	assert.Equal(t, "4.2.0", d.Get("reported_version"))
+
+	delete(api.edges, "gw1")
+	diags = r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Empty(t, d.Id())
+}
+
+func TestGatewayUpdateKeepsSoftware(t *testing.T) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1", Name: "gw", Swversion: "4.2.0"})
+	r := mustResource(t, resourceGateway)
+
+	// The state predates an upgrade made by another resource.
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":      "gw",
+		"swversion": "4.1.0",
+	})
+	d.SetId("gw1")
+	d = r.Data(d.State())
+	require.NoError(t, d.Set("name", "renamed"))
+
+	diags := r.UpdateContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "renamed", api.edge("gw1").Name)
+	assert.Equal(t, "4.2.0", api.edge("gw1").Swversion)
+}
+
+func TestCompareVersions(t *testing.T) {
+	sorted := []string{"4.2", "4.2.9.1500", "4.2.9-beta1", "4.2.9-beta10", "4.2.10.1000", "10.0", "R4.2"}
+
+	for i, a := range sorted {
+		for j, b := range sorted {
+			var want int
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			assert.Equal(t, want, compareVersions(a, b), "%s vs %s", a, b)
+		}
+	}
+}
+
+func TestDataSourceSoftwareVersions(t *testing.T) {
+	virtual, hw := swagger.I_X_VIRTUAL_EdgeModel, swagger.I_X1000_W_EdgeModel
+	client := testAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(swagger.EdgesList{LastPage: true, Data: []swagger.Edge{
+			{Id: "1", Model: &virtual, Swversion: "4.2.10.1000"},
+			{Id: "2", Model: &virtual, Swversion: "4.2.9.1500"},
+			{Id: "3", Model: &hw, Swversion: "4.2.9.1500"},
+			{Id: "4", Model: &virtual, Swversion: "4.2.10.1000"},
+			{Id: "5", Model: &hw},
+		}})
+	}))
+	ds := mustResource(t, dataSourceSoftwareVersions)
+
+	d := schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{})
+	diags := ds.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, []interface{}{"4.2.9.1500", "4.2.10.1000"}, d.Get("versions"))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"model": "iX1000W", "versions": []interface{}{"4.2.9.1500"}},
+		map[string]interface{}{"model": "iXVirtual", "versions": []interface{}{"4.2.9.1500", "4.2.10.1000"}},
+	}, d.Get("models"))
+	assert.NotEmpty(t, d.Id())
+
+	d = schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{"model": "iX1000W"})
+	diags = ds.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, []interface{}{"4.2.9.1500"}, d.Get("versions"))
+	assert.Len(t, d.Get("models"), 1)
+}
@@ -0,0 +1,148 @@
+package bwan
+
+import (
+	"context"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const staticRoutesList = "static routes"
+
+func staticRouteKey(route swagger.StaticRoute) string {
+	return route.Destination
+}
+
+func (rt _resourceGatewayStaticRoutes) setStaticRoutes(
+	d *schema.ResourceData, prior resourceGatewayStaticRoutesInput, gateway swagger.Edge) error {
+	return ApplyBinderResourceData(rt.Binder, d, resourceGatewayStaticRoutesInput{
+		GatewayId: prior.GatewayId,
+		Routes:    orderByKey(prior.Routes, gateway.StaticRoutes, staticRouteKey),
+	})
+}
+
+func (rt _resourceGatewayStaticRoutes) resourceGatewayStaticRoutesRead(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	edgeInput, err := ApplyBinderInputResourceData[resourceGatewayStaticRoutesInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	apiSvc := m.(*apiClient)
+
+	gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
+	if err != nil {
+		if apiSvc.isNotFound(err, resp, "/edges/"+edgeInput.GatewayId) {
+			d.SetId("")
+			return diags
+		}
+		return apiError("GetEdgeById", resp, err, rt.Binder)
+	}
+
+	err = rt.setStaticRoutes(d, edgeInput, gateway)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(edgeInput.GatewayId)
+	return diags
+}
+
+func (rt _resourceGatewayStaticRoutes) resourceGatewayStaticRoutesUpdate(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	edgeInput, err := ApplyBinderInputResourceData[resourceGatewayStaticRoutesInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	for i := range edgeInput.Routes {
+		_resourceGatewayStaticRoute{}.fixupStaticRouteConfig(&edgeInput.Routes[i])
+	}
+
+	apiSvc := m.(*apiClient)
+	gateway, err := apiSvc.writer.update(ctx, edgeInput.GatewayId, edgeMutation{
+		Lists: []string{"staticRoutes"},
+		Apply: func(gateway *swagger.Edge) error {
+			gateway.StaticRoutes = edgeInput.Routes
+			return nil
+		},
+	})
+	if err != nil {
+		return edgeWriteError(err, rt.Binder)
+	}
+
+	err = rt.setStaticRoutes(d, edgeInput, gateway)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(edgeInput.GatewayId)
+	return diags
+}
+
+func (rt _resourceGatewayStaticRoutes) resourceGatewayStaticRoutesDelete(
+	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	edgeInput, err := ApplyBinderInputResourceData[resourceGatewayStaticRoutesInput](rt.InputBinder, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	apiSvc := m.(*apiClient)
+	_, err = apiSvc.writer.update(ctx, edgeInput.GatewayId, edgeMutation{
+		Lists: []string{"staticRoutes"},
+		Apply: func(gateway *swagger.Edge) error {
+			gateway.StaticRoutes = nil
+			return nil
+		},
+	})
+	if err != nil && !apiSvc.edgeGone(err, edgeInput.GatewayId) {
+		return edgeWriteError(err, rt.Binder)
+	}
+
+	d.SetId("")
+	return diags
+}
+
+type _resourceGatewayStaticRoutes struct {
+	Binder      []FieldBinder
+	InputBinder []FieldBinder
+}
+
+type resourceGatewayStaticRoutesInput struct {
+	GatewayId string
+	Routes    []swagger.StaticRoute `json:"route"`
+}
+
+func resourceGatewayStaticRoutes() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(resourceGatewayStaticRoutesInput{}, Cfg{
+		"gateway_id":        {Schema: schema.Schema{Required: true, ForceNew: true}},
+		"route":             {Schema: schema.Schema{Optional: true}},
+		"route.destination": {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4CIDR},
+		"route.device":      {Schema: schema.Schema{Required: true}},
+		"route.nhop":        {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rt := _resourceGatewayStaticRoutes{Binder: binder, InputBinder: inputBinder}
+
+	return &schema.Resource{
+		CreateContext: rt.resourceGatewayStaticRoutesUpdate,
+		ReadContext:   rt.resourceGatewayStaticRoutesRead,
+		UpdateContext: rt.resourceGatewayStaticRoutesUpdate,
+		DeleteContext: rt.resourceGatewayStaticRoutesDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateComposite("gateway_id"),
+		},
+		CustomizeDiff: uniqueKeys("route", "destination"),
+		Schema:        swaggerSchema,
+	}, nil
+}
@@ -0,0 +1,203 @@
+package bwan
+
+import (
+	"context"
+	"testing"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testRoute(destination string) map[string]interface{} {
+	return map[string]interface{}{
+		"destination": destination,
+		"device":      "GE1",
+		"nhop":        "10.0.0.1",
+	}
+}
+
+func TestResourceGatewayStaticRoutes(t *testing.T) {
+	api, client := newFakeEdgeAPI(t, swagger.Edge{
+		Id: "gw1",
+		StaticRoutes: []swagger.StaticRoute{
+			{Destination: "192.168.0.0/24", Device: "GE2", Nhop: "10.0.1.1", Cost: 5},
+		},
+	})
+
+	r := mustResource(t, resourceGatewayStaticRoutes)
+	config := map[string]interface{}{
+		"gateway_id": "gw1",
+		"route": []interface{}{
+			testRoute("10.1.0.0/16"),
+			testRoute("10.2.0.0/16"),
+		},
+	}
+	d := schema.TestResourceDataRaw(t, r.Schema, config)
+
+	// The route added elsewhere is replaced along with the rest.
+	diags := r.CreateContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, "gw1", d.Id())
+	assert.Equal(t, 1, api.count("PUT"))
+	assert.Equal(t, []swagger.StaticRoute{
+		{Destination: "10.1.0.0/16", Device: "GE1", Nhop: "10.0.0.1", Cost: 1},
+		{Destination: "10.2.0.0/16", Device: "GE1", Nhop: "10.0.0.1", Cost: 1},
+	}, api.edge("gw1").StaticRoutes)
+
+	// The API order does not leak into the state, and routes added
+	// elsewhere are read so that they are planned for removal.
+	api.edges["gw1"].StaticRoutes = []swagger.StaticRoute{
+		{Destination: "10.3.0.0/16", Device: "GE1", Nhop: "10.0.0.1", Cost: 1},
+		{Destination: "10.2.0.0/16", Device: "GE1", Nhop: "10.0.0.1", Cost: 1},
+		{Destination: "10.1.0.0/16", Device: "GE1", Nhop: "10.0.0.1", Cost: 1},
+	}
+	diags = r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, 3, d.Get("route.#"))
+	assert.Equal(t, "10.1.0.0/16", d.Get("route.0.destination"))
+	assert.Equal(t, "10.2.0.0/16", d.Get("route.1.destination"))
+	assert.Equal(t, "10.3.0.0/16", d.Get("route.2.destination"))
+
+	diff, err := r.SimpleDiff(context.Background(), d.State(), terraform.NewResourceConfigRaw(config), client)
+	require.NoError(t, err)
+	require.NotNil(t, diff)
+	assert.Equal(t, "2", diff.Attributes["route.#"].New)
+	assert.True(t, diff.Attributes["route.2.destination"].NewRemoved)
+
+	state, diags := r.Apply(context.Background(), d.State(), diff, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "2", state.Attributes["route.#"])
+	assert.Equal(t, 2, api.count("PUT"))
+	assert.Equal(t, []string{"10.1.0.0/16", "10.2.0.0/16"},
+		itemKeys(api.edge("gw1").StaticRoutes, staticRouteKey))
+
+	// Deleting empties the route table, which needs an explicit empty list.
+	diags = r.DeleteContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Empty(t, api.edge("gw1").StaticRoutes)
+	assert.Equal(t, `{"staticRoutes":[]}`, api.bodies[len(api.bodies)-1])
+}
+
+func TestResourceGatewayStaticRoutesConflicts(t *testing.T) {
+	_, client := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"})
+
+	r := mustResource(t, resourceGatewayStaticRoutes)
+	_, err := r.SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"gateway_id": "gw1",
+		"route": []interface{}{
+			testRoute("10.1.0.0/16"),
+			testRoute("10.1.0.0/16"),
+		},
+	}), client)
+	assert.ErrorContains(t, err, `route blocks must have a unique destination, "10.1.0.0/16" is used more than once`)
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"gateway_id": "gw1",
+		"route":      []interface{}{testRoute("10.1.0.0/16")},
+	})
+	diags := r.CreateContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	// The per-route resource cannot create a route the gateway has.
+	single := mustResource(t, resourceGatewayStaticRoute)
+	_, err = single.SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(
+		map[string]interface{}{"gateway_id": "gw1", "destination": "10.1.0.0/16", "device": "GE1", "nhop": "10.0.0.1"},
+	), client)
+	assert.ErrorContains(t, err, `static route "10.1.0.0/16" already exists on gateway gw1: import it, `+
+		`or declare it in netskopebwan_gateway_static_routes if that resource manages the static routes of the gateway`)
+
+	_, err = single.SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(
+		map[string]interface{}{"gateway_id": "gw1", "destination": "10.2.0.0/16", "device": "GE1", "nhop": "10.0.0.1"},
+	), client)
+	assert.NoError(t, err)
+
+	_, err = single.SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(
+		map[string]interface{}{"gateway_id": "gw2", "destination": "10.1.0.0/16", "device": "GE1", "nhop": "10.0.0.1"},
+	), client)
+	assert.NoError(t, err)
+}
+
+// TestResourceGatewayStaticRoutesOrder checks that a route added by the
+// per-route resource is planned for removal by the next plan of the
+// authoritative resource, whichever of them was applied first.
+func TestResourceGatewayStaticRoutesOrder(t *testing.T) {
+	for _, singleFirst := range []bool{true, false} {
+		api, _ := newFakeEdgeAPI(t, swagger.Edge{Id: "gw1"})
+		r := mustResource(t, resourceGatewayStaticRoutes)
+		single := mustResource(t, resourceGatewayStaticRoute)
+		config := map[string]interface{}{
+			"gateway_id": "gw1",
+			"route":      []interface{}{testRoute("10.1.0.0/16")},
+		}
+
+		// Each resource is applied by its own provider instance.
+		d := schema.TestResourceDataRaw(t, r.Schema, config)
+		ds := schema.TestResourceDataRaw(t, single.Schema, map[string]interface{}{
+			"gateway_id": "gw1", "destination": "10.2.0.0/16", "device": "GE1", "nhop": "10.0.0.1",
+		})
+		apply := []func() diag.Diagnostics{
+			func() diag.Diagnostics { return r.CreateContext(context.Background(), d, testAPIClient(t, api)) },
+			func() diag.Diagnostics { return single.CreateContext(context.Background(), ds, testAPIClient(t, api)) },
+		}
+		if singleFirst {
+			apply[0], apply[1] = apply[1], apply[0]
+		}
+		for _, f := range apply {
+			diags := f()
+			require.False(t, diags.HasError(), "%v", diags)
+		}
+
+		client := testAPIClient(t, api)
+		diags := r.ReadContext(context.Background(), d, client)
+		require.False(t, diags.HasError(), "%v", diags)
+		diff, err := r.SimpleDiff(context.Background(), d.State(), terraform.NewResourceConfigRaw(config), client)
+		require.NoError(t, err)
+		if singleFirst {
+			// The route was replaced by the authoritative resource already.
+			assert.True(t, diff.Empty())
+			assert.Equal(t, []string{"10.1.0.0/16"}, itemKeys(api.edge("gw1").StaticRoutes, staticRouteKey))
+			continue
+		}
+		require.NotNil(t, diff)
+		assert.True(t, diff.Attributes["route.1.destination"].NewRemoved)
+
+		_, diags = r.Apply(context.Background(), d.State(), diff, client)
+		require.False(t, diags.HasError(), "%v", diags)
+		assert.Equal(t, []string{"10.1.0.0/16"}, itemKeys(api.edge("gw1").StaticRoutes, staticRouteKey))
+	}
+}
+
+func TestResourceGatewayStaticRoutesImport(t *testing.T) {
+	_, client := newFakeEdgeAPI(t, swagger.Edge{
+		Id: "gw1",
+		StaticRoutes: []swagger.StaticRoute{
+			{Destination: "10.1.0.0/16", Device: "GE1", Nhop: "10.0.0.1", Cost: 1},
+			{Destination: "10.2.0.0/16", Device: "GE2", Nhop: "10.0.1.1", Cost: 5},
+		},
+	})
+	r := mustResource(t, resourceGatewayStaticRoutes)
+
+	d := r.Data(nil)
+	d.SetId("gw1")
+	imported, err := r.Importer.StateContext(context.Background(), d, client)
+	require.NoError(t, err)
+	require.Len(t, imported, 1)
+
+	d = imported[0]
+	diags := r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, "gw1", d.Get("gateway_id"))
+	assert.Equal(t, 2, d.Get("route.#"))
+	assert.Equal(t, "10.2.0.0/16", d.Get("route.1.destination"))
+	assert.Equal(t, "GE2", d.Get("route.1.device"))
+	assert.Equal(t, 5, d.Get("route.1.cost"))
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
-	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -28,6 +27,27 @@ func (rt _resourceGatewayStaticRoute) getExistingStaticRoute(
 	return -1
 }
 
+var staticRouteItem = edgeItem{
+	What:  "static route",
+	List:  staticRoutesList,
+	Owner: "netskopebwan_gateway_static_routes",
+	Key:   "destination",
+	Keys: func(gateway *swagger.Edge) []string {
+		return itemKeys(gateway.StaticRoutes, staticRouteKey)
+	},
+}
+
+// item returns the edgeMutation.Item of a mutation of the route.
+func (rt _resourceGatewayStaticRoute) item(route swagger.StaticRoute) func(gateway *swagger.Edge) interface{} {
+	return func(gateway *swagger.Edge) interface{} {
+		index := rt.getExistingStaticRoute(gateway.StaticRoutes, route)
+		if index < 0 {
+			return nil
+		}
+		return gateway.StaticRoutes[index]
+	}
+}
+
 func (rt _resourceGatewayStaticRoute) resourceGatewayStaticRouteRead(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
@@ -39,20 +59,23 @@ func (rt _resourceGatewayStaticRoute) resourceGatewayStaticRouteRead(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	if len(edgeInput.GatewayId) > 0 {
-		gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
+		gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
 		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
+			if apiSvc.isNotFound(err, resp, "/edges/"+edgeInput.GatewayId) {
+				d.SetId("")
+				return diags
 			}
-			return diag.FromErr(err)
+			return apiError("GetEdgeById", resp, err, rt.Binder)
 		}
 		index := rt.getExistingStaticRoute(gateway.StaticRoutes, edgeInput.StaticRoute)
-		if index >= 0 {
-			routeConfig = gateway.StaticRoutes[index]
+		if index < 0 {
+			d.SetId("")
+			return removedOutOfBand(gateway, fmt.Sprintf("Static route %q", edgeInput.Destination))
 		}
+		routeConfig = gateway.StaticRoutes[index]
 	} else {
 		return diag.FromErr(err)
 	}
@@ -63,7 +86,7 @@ func (rt _resourceGatewayStaticRoute) resourceGatewayStaticRouteRead(
 		return diag.FromErr(err)
 	}
 
-	d.SetId(utils.Hash(routeConfig))
+	d.SetId(compositeId(edgeInput.GatewayId, edgeInput.Destination))
 	return diags
 }
 
@@ -78,43 +101,27 @@ func (rt _resourceGatewayStaticRoute) resourceGatewayStaticRouteUpdate(
 		return diag.FromErr(err)
 	}
 	rt.fixupStaticRouteConfig(&edgeInput.StaticRoute)
-	apiSvc := m.(*swagger.APIClient)
-	lock := utils.Mutex.Get(edgeInput.GatewayId)
-	lock.Lock()
-	defer lock.Unlock()
-	gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
+	apiSvc := m.(*apiClient)
+	gateway, err := apiSvc.writer.update(ctx, edgeInput.GatewayId, edgeMutation{
+		Lists: []string{"staticRoutes"},
+		Item:  rt.item(edgeInput.StaticRoute),
+		What:  fmt.Sprintf("static route %q", edgeInput.Destination),
+		Apply: func(gateway *swagger.Edge) error {
+			index := rt.getExistingStaticRoute(gateway.StaticRoutes, edgeInput.StaticRoute)
+			if index >= 0 {
+				gateway.StaticRoutes[index] = edgeInput.StaticRoute
+			} else {
+				gateway.StaticRoutes = append(gateway.StaticRoutes, edgeInput.StaticRoute)
+			}
+			return nil
+		},
+	})
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return edgeWriteError(err, rt.Binder)
 	}
-	existRoutes := gateway.StaticRoutes
-	index := rt.getExistingStaticRoute(existRoutes, edgeInput.StaticRoute)
+	index := rt.getExistingStaticRoute(gateway.StaticRoutes, edgeInput.StaticRoute)
 	if index >= 0 {
-		existRoutes[index] = edgeInput.StaticRoute
-	} else {
-		existRoutes = append(existRoutes, edgeInput.StaticRoute)
-	}
-	addGwInput := swagger.UpdateEdgeInput{
-		StaticRoutes: existRoutes,
-	}
-
-	if len(edgeInput.GatewayId) > 0 {
-		gateway, _, err := apiSvc.EdgesApi.UpdateEdgeById(ctx, addGwInput, edgeInput.GatewayId, nil)
-		if err != nil {
-			if serr, ok := err.(swagger.GenericSwaggerError); ok {
-				return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-			}
-			return diag.FromErr(err)
-		}
-		index := rt.getExistingStaticRoute(gateway.StaticRoutes, edgeInput.StaticRoute)
-		if index >= 0 {
-			routeConfig = gateway.StaticRoutes[index]
-		}
-
-	} else {
-		return diag.FromErr(err)
+		routeConfig = gateway.StaticRoutes[index]
 	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, routeConfig)
@@ -122,14 +129,13 @@ func (rt _resourceGatewayStaticRoute) resourceGatewayStaticRouteUpdate(
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	d.SetId(utils.Hash(routeConfig))
+	d.SetId(compositeId(edgeInput.GatewayId, edgeInput.Destination))
 	return diags
 }
 
 func (rt _resourceGatewayStaticRoute) resourceGatewayStaticRouteDelete(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	var routeConfig swagger.StaticRoute
 	var err error
 
 	edgeInput, err := ApplyBinderInputResourceData[resourceGatewayStaticRouteInput](rt.InputBinder, d)
@@ -137,48 +143,21 @@ func (rt _resourceGatewayStaticRoute) resourceGatewayStaticRouteDelete(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
-	lock := utils.Mutex.Get(edgeInput.GatewayId)
-	lock.Lock()
-	defer lock.Unlock()
-	gateway, _, err := apiSvc.EdgesApi.GetEdgeById(ctx, edgeInput.GatewayId, nil)
-	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
-	}
-	existRoutes := gateway.StaticRoutes
-	index := rt.getExistingStaticRoute(existRoutes, edgeInput.StaticRoute)
-	if index >= 0 {
-		existRoutes = append(existRoutes[:index], existRoutes[index+1:]...)
-
-		addGwInput := swagger.UpdateEdgeInput{
-			StaticRoutes: existRoutes,
-		}
-
-		if len(edgeInput.GatewayId) > 0 {
-			gateway, _, err := apiSvc.EdgesApi.UpdateEdgeById(ctx, addGwInput, edgeInput.GatewayId, nil)
-			if err != nil {
-				if serr, ok := err.(swagger.GenericSwaggerError); ok {
-					return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-				}
-				return diag.FromErr(err)
-			}
+	apiSvc := m.(*apiClient)
+	_, err = apiSvc.writer.update(ctx, edgeInput.GatewayId, edgeMutation{
+		Lists: []string{"staticRoutes"},
+		Item:  rt.item(edgeInput.StaticRoute),
+		What:  fmt.Sprintf("static route %q", edgeInput.Destination),
+		Apply: func(gateway *swagger.Edge) error {
 			index := rt.getExistingStaticRoute(gateway.StaticRoutes, edgeInput.StaticRoute)
 			if index >= 0 {
-				routeConfig = gateway.StaticRoutes[index]
+				gateway.StaticRoutes = append(gateway.StaticRoutes[:index], gateway.StaticRoutes[index+1:]...)
 			}
-
-		} else {
-			return diag.FromErr(err)
-		}
-	}
-
-	err = ApplyBinderResourceData(rt.Binder, d, routeConfig)
-
+			return nil
+		},
+	})
 	if err != nil {
-		return diag.FromErr(err)
+		return edgeWriteError(err, rt.Binder)
 	}
 	d.SetId("")
 	return diags
@@ -194,13 +173,16 @@ type resourceGatewayStaticRouteInput struct {
 	swagger.StaticRoute
 }
 
-func resourceGatewayStaticRoute() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(resourceGatewayStaticRouteInput{}, Cfg{
+func resourceGatewayStaticRoute() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(resourceGatewayStaticRouteInput{}, Cfg{
 		"gateway_id":  {Schema: schema.Schema{Required: true}},
-		"destination": {Schema: schema.Schema{Required: true}},
+		"destination": {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4CIDR},
 		"device":      {Schema: schema.Schema{Required: true}},
-		"nhop":        {Schema: schema.Schema{Required: true}},
+		"nhop":        {Schema: schema.Schema{Required: true}, Validate: ValidateIPv4},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _resourceGatewayStaticRoute{Binder: binder, InputBinder: inputBinder}
 
@@ -209,6 +191,12 @@ func resourceGatewayStaticRoute() *schema.Resource {
 		ReadContext:   rt.resourceGatewayStaticRouteRead,
 		UpdateContext: rt.resourceGatewayStaticRouteUpdate,
 		DeleteContext: rt.resourceGatewayStaticRouteDelete,
-		Schema:        swaggerSchema,
-	}
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateComposite("gateway_id", "destination"),
+		},
+		CustomizeDiff:  staticRouteItem.checkNew(),
+		Schema:         swaggerSchema,
+		SchemaVersion:  1,
+		StateUpgraders: compositeIdStateUpgraders(swaggerSchema, "gateway_id", "destination"),
+	}, nil
 }
@@ -2,7 +2,6 @@ package bwan
 
 import (
 	"context"
-	"fmt"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 
@@ -14,7 +13,7 @@ func (rt _resourcePolicy) resourcePolicyCreate(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 	policyInput, err := ApplyBinderInputResourceData[swagger.Policy](rt.InputBinder, d)
 	if err != nil {
 		return diag.FromErr(err)
@@ -26,12 +25,9 @@ func (rt _resourcePolicy) resourcePolicyCreate(
 		Config: policyInput.Config,
 	}
 
-	policy, _, err := apiSvc.PoliciesApi.AddPolicy(ctx, addPolicyInput, nil)
+	policy, resp, err := apiSvc.PoliciesApi.AddPolicy(ctx, addPolicyInput, nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return apiError("AddPolicy", resp, err, rt.Binder)
 	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, policy)
@@ -50,18 +46,15 @@ func (rt _resourcePolicy) resourcePolicyRead(
 	var diags diag.Diagnostics
 	var err error
 
-	apiSvc := m.(*swagger.APIClient)
-	policyInput, err := ApplyBinderInputResourceData[swagger.Policy](rt.InputBinder, d)
-	if err != nil {
-		return diag.FromErr(err)
-	}
+	apiSvc := m.(*apiClient)
 
-	policy, _, err := apiSvc.PoliciesApi.GetPolicyById(ctx, policyInput.Id, nil)
+	policy, resp, err := apiSvc.PoliciesApi.GetPolicyById(ctx, d.Id(), nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
+		if apiSvc.isNotFound(err, resp, "/policies/"+d.Id()) {
+			d.SetId("")
+			return diags
 		}
-		return diag.FromErr(err)
+		return apiError("GetPolicyById", resp, err, rt.Binder)
 	}
 	err = ApplyBinderResourceData(rt.Binder, d, policy)
 	if err != nil {
@@ -82,13 +75,10 @@ func (rt _resourcePolicy) resourcePolicyUpdate(
 		return diag.FromErr(err)
 	}
 
-	apiSvc := m.(*swagger.APIClient)
-	policy, _, err := apiSvc.PoliciesApi.UpdatePolicyById(ctx, policyInput, policyInput.Id, nil)
+	apiSvc := m.(*apiClient)
+	policy, resp, err := apiSvc.PoliciesApi.UpdatePolicyById(ctx, policyInput, d.Id(), nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return apiError("UpdatePolicyById", resp, err, rt.Binder)
 	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, policy)
@@ -104,18 +94,10 @@ func (rt _resourcePolicy) resourcePolicyDelete(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	apiSvc := m.(*swagger.APIClient)
-	policyInput, err := ApplyBinderInputResourceData[swagger.Policy](rt.InputBinder, d)
-
+	apiSvc := m.(*apiClient)
+	_, resp, err := apiSvc.PoliciesApi.DeletePolicyById(ctx, d.Id(), nil)
 	if err != nil {
-		return diag.FromErr(err)
-	}
-	_, _, err = apiSvc.PoliciesApi.DeletePolicyById(ctx, policyInput.Id, nil)
-	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return apiError("DeletePolicyById", resp, err, rt.Binder)
 	}
 	d.SetId("")
 	return diags
@@ -126,10 +108,13 @@ type _resourcePolicy struct {
 	InputBinder []FieldBinder
 }
 
-func resourcePolicy() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(swagger.Policy{}, Cfg{
+func resourcePolicy() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(swagger.Policy{}, Cfg{
 		"name": {Schema: schema.Schema{Required: true}},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _resourcePolicy{Binder: binder, InputBinder: inputBinder}
 
@@ -138,6 +123,9 @@ func resourcePolicy() *schema.Resource {
 		ReadContext:   rt.resourcePolicyRead,
 		UpdateContext: rt.resourcePolicyUpdate,
 		DeleteContext: rt.resourcePolicyDelete,
-		Schema:        swaggerSchema,
-	}
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: swaggerSchema,
+	}, nil
 }
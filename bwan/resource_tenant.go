@@ -2,7 +2,6 @@ package bwan
 
 import (
 	"context"
-	"fmt"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 
@@ -15,19 +14,16 @@ func (rt _resourceTenant) resourceTenantCreate(
 	var diags diag.Diagnostics
 	var err error
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
 	tenantInput, err := ApplyBinderInputResourceData[swagger.Tenant](rt.InputBinder, d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	tenant, _, err := apiSvc.TenantsApi.AddTenant(ctx, tenantInput, nil)
+	tenant, resp, err := apiSvc.TenantsApi.AddTenant(ctx, tenantInput, nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return apiError("AddTenant", resp, err, rt.Binder)
 	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, tenant)
@@ -44,19 +40,15 @@ func (rt _resourceTenant) resourceTenantRead(
 	var diags diag.Diagnostics
 	var err error
 
-	apiSvc := m.(*swagger.APIClient)
-
-	tenantInput, err := ApplyBinderInputResourceData[swagger.Tenant](rt.InputBinder, d)
-	if err != nil {
-		return diag.FromErr(err)
-	}
+	apiSvc := m.(*apiClient)
 
-	tenant, _, err := apiSvc.TenantsApi.GetTenantById(ctx, tenantInput.Id, nil)
+	tenant, resp, err := apiSvc.TenantsApi.GetTenantById(ctx, d.Id(), nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
+		if apiSvc.isNotFound(err, resp, "/tenants/"+d.Id()) {
+			d.SetId("")
+			return diags
 		}
-		return diag.FromErr(err)
+		return apiError("GetTenantById", resp, err, rt.Binder)
 	}
 	err = ApplyBinderResourceData(rt.Binder, d, tenant)
 	if err != nil {
@@ -71,18 +63,15 @@ func (rt _resourceTenant) resourceTenantUpdate(
 	var diags diag.Diagnostics
 	var err error
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 	tenantInput, err := ApplyBinderInputResourceData[swagger.Tenant](rt.InputBinder, d)
-	if err != nil || tenantInput.Id == "" {
+	if err != nil || d.Id() == "" {
 		return diag.FromErr(err)
 	}
 
-	tenant, _, err := apiSvc.TenantsApi.UpdateTenantById(ctx, tenantInput, tenantInput.Id, nil)
+	tenant, resp, err := apiSvc.TenantsApi.UpdateTenantById(ctx, tenantInput, d.Id(), nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return apiError("UpdateTenantById", resp, err, rt.Binder)
 	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, tenant)
@@ -98,19 +87,11 @@ func (rt _resourceTenant) resourceTenantDelete(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
-	tenantInput, err := ApplyBinderInputResourceData[swagger.Tenant](rt.InputBinder, d)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	_, _, err = apiSvc.TenantsApi.DeleteTenantById(ctx, tenantInput.Id, nil)
+	_, resp, err := apiSvc.TenantsApi.DeleteTenantById(ctx, d.Id(), nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return apiError("DeleteTenantById", resp, err, rt.Binder)
 	}
 	d.SetId("")
 	return diags
@@ -121,10 +102,13 @@ type _resourceTenant struct {
 	InputBinder []FieldBinder
 }
 
-func resourceTenant() *schema.Resource {
-	swaggerSchema, binder, inputBinder := ReflectSchema(swagger.Tenant{}, Cfg{
+func resourceTenant() (*schema.Resource, error) {
+	swaggerSchema, binder, inputBinder, err := ReflectSchema(swagger.Tenant{}, Cfg{
 		"name": {Schema: schema.Schema{Required: true}},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _resourceTenant{Binder: binder, InputBinder: inputBinder}
 
@@ -133,6 +117,9 @@ func resourceTenant() *schema.Resource {
 		ReadContext:   rt.resourceTenantRead,
 		UpdateContext: rt.resourceTenantUpdate,
 		DeleteContext: rt.resourceTenantDelete,
-		Schema:        swaggerSchema,
-	}
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: swaggerSchema,
+	}, nil
 }
@@ -2,7 +2,6 @@ package bwan
 
 import (
 	"context"
-	"fmt"
 
 	swagger "github.com/infiotinc/netskopebwan-go-client"
 
@@ -14,17 +13,14 @@ func (rt _resourceUser) resourceUserCreate(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 	userInput, err := ApplyBinderInputResourceData[swagger.User](rt.InputBinder, d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	user, _, err := apiSvc.UsersApi.AddUser(ctx, userInput, nil)
+	user, resp, err := apiSvc.UsersApi.AddUser(ctx, userInput, nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return apiError("AddUser", resp, err, rt.Binder)
 	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, user)
@@ -40,22 +36,17 @@ func (rt _resourceUser) resourceUserCreate(
 func (rt _resourceUser) resourceUserRead(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	var user swagger.User
 	var err error
 
-	userInput, err := ApplyBinderInputResourceData[swagger.User](rt.InputBinder, d)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 
-	user, _, err = apiSvc.UsersApi.GetUserById(ctx, userInput.Id, nil)
+	user, resp, err := apiSvc.UsersApi.GetUserById(ctx, d.Id(), nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
+		if apiSvc.isNotFound(err, resp, "/users/"+d.Id()) {
+			d.SetId("")
+			return diags
 		}
-		return diag.FromErr(err)
+		return apiError("GetUserById", resp, err, rt.Binder)
 	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, user)
@@ -71,17 +62,14 @@ func (rt _resourceUser) resourceUserUpdate(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	apiSvc := m.(*swagger.APIClient)
+	apiSvc := m.(*apiClient)
 	userInput, err := ApplyBinderInputResourceData[swagger.User](rt.InputBinder, d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	user, _, err := apiSvc.UsersApi.UpdateUserById(ctx, userInput, userInput.Id, nil)
+	user, resp, err := apiSvc.UsersApi.UpdateUserById(ctx, userInput, d.Id(), nil)
 	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return apiError("UpdateUserById", resp, err, rt.Binder)
 	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, user)
@@ -97,17 +85,10 @@ func (rt _resourceUser) resourceUserDelete(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	apiSvc := m.(*swagger.APIClient)
-	userInput, err := ApplyBinderInputResourceData[swagger.User](rt.InputBinder, d)
+	apiSvc := m.(*apiClient)
+	user, resp, err := apiSvc.UsersApi.DeleteUserById(ctx, d.Id(), nil)
 	if err != nil {
-		return diag.FromErr(err)
-	}
-	user, _, err := apiSvc.UsersApi.DeleteUserById(ctx, userInput.Id, nil)
-	if err != nil {
-		if serr, ok := err.(swagger.GenericSwaggerError); ok {
-			return diag.FromErr(fmt.Errorf("%s", serr.Body()))
-		}
-		return diag.FromErr(err)
+		return apiError("DeleteUserById", resp, err, rt.Binder)
 	}
 
 	err = ApplyBinderResourceData(rt.Binder, d, user)
@@ -124,14 +105,17 @@ type _resourceUser struct {
 	InputBinder []FieldBinder
 }
 
-func resourceUser() *schema.Resource {
-	swaggerSchema, binder, swaggerInputBinder := ReflectSchema(swagger.User{}, Cfg{
+func resourceUser() (*schema.Resource, error) {
+	swaggerSchema, binder, swaggerInputBinder, err := ReflectSchema(swagger.User{}, Cfg{
 		"name": {
 			Schema: schema.Schema{
 				Required: true,
 			},
 		},
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	rt := _resourceUser{Binder: binder, InputBinder: swaggerInputBinder}
 
@@ -140,6 +124,9 @@ func resourceUser() *schema.Resource {
 		ReadContext:   rt.resourceUserRead,
 		UpdateContext: rt.resourceUserUpdate,
 		DeleteContext: rt.resourceUserDelete,
-		Schema:        swaggerSchema,
-	}
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: swaggerSchema,
+	}, nil
 }
@@ -0,0 +1,89 @@
+package bwan
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// Validators for FieldCfg.Validate.
+var (
+	ValidateIPv4 = validation.IsIPv4Address
+	ValidatePort = validation.IsPortNumber
+	ValidateVLAN = validation.IntBetween(1, 4094)
+	// ValidateMTU accepts MTUs from the IPv4 minimum up to jumbo frames.
+	ValidateMTU = validation.IntBetween(68, 9216)
+)
+
+// ValidateASN accepts AS numbers from 1 up to 2147483647, the largest the
+// client can send as its AS numbers are int32. Larger 4 byte AS numbers
+// would wrap around to negative numbers. Unlike validation.IntBetween it
+// compares in 64 bits, so that larger values are rejected on 32-bit
+// platforms as well.
+func ValidateASN(i interface{}, k string) ([]string, []error) {
+	var v int64
+	switch n := i.(type) {
+	case int:
+		v = int64(n)
+	case int32:
+		v = int64(n)
+	case int64:
+		v = n
+	default:
+		return nil, []error{fmt.Errorf("expected type of %s to be integer", k)}
+	}
+
+	if v < 1 || v > math.MaxInt32 {
+		return nil, []error{fmt.Errorf("expected %s to be an AS number from 1 to %d, got %d", k, math.MaxInt32, v)}
+	}
+
+	return nil, nil
+}
+
+// ValidateIPv4CIDR accepts IPv4 networks and addresses in CIDR notation,
+// e.g. "10.0.0.0/8" or "10.0.0.1/24".
+func ValidateIPv4CIDR(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+
+	ip, _, err := net.ParseCIDR(v)
+	if err != nil || ip.To4() == nil {
+		return nil, []error{fmt.Errorf("expected %s to be an IPv4 CIDR such as \"10.0.0.0/8\", got %q", k, v)}
+	}
+
+	return nil, nil
+}
+
+// ValidateDuration accepts durations such as "30s" or "1h30m".
+func ValidateDuration(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+
+	if _, err := time.ParseDuration(v); err != nil {
+		return nil, []error{fmt.Errorf("expected %s to be a duration such as \"30s\": %w", k, err)}
+	}
+
+	return nil, nil
+}
+
+// ValidateOneOf accepts the given values only, compared case-sensitively.
+func ValidateOneOf(values ...string) schema.SchemaValidateFunc {
+	return validation.StringInSlice(values, false)
+}
+
+// ValidateRegexp accepts strings matching the expression, describing it as
+// what in errors, e.g. "a MAC address".
+func ValidateRegexp(expr, what string) schema.SchemaValidateFunc {
+	re := regexp.MustCompile(expr)
+
+	return validation.StringMatch(re, fmt.Sprintf("expected %s", what))
+}
@@ -0,0 +1,48 @@
+package bwan
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateASN(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		valid bool
+	}{
+		{0, false},
+		{1, true},
+		{65535, true},
+		{int64(65536), true},
+		{int64(2147483647), true},
+		// The client sends AS numbers as int32.
+		{int64(2147483648), false},
+		{int64(4200000000), false},
+		{int64(4294967295), false},
+		{int64(-1), false},
+		{int32(64512), true},
+		{"65000", false},
+	}
+	for _, test := range tests {
+		_, errs := ValidateASN(test.value, "remote_as")
+		assert.Equal(t, test.valid, len(errs) == 0, "%T %v: %v", test.value, test.value, errs)
+	}
+}
+
+func TestInterfaceSpecEnums(t *testing.T) {
+	r := mustResource(t, resourceGatewayInterface)
+	addresses := r.Schema["addresses"].Elem.(*schema.Resource).Schema
+
+	for name, s := range map[string]*schema.Schema{
+		"mode":               r.Schema["mode"],
+		"type":               r.Schema["type"],
+		"mtu_discovery":      r.Schema["mtu_discovery"],
+		"address_assignment": addresses["address_assignment"],
+		"address_family":     addresses["address_family"],
+	} {
+		assert.NotNil(t, s.ValidateDiagFunc, name)
+	}
+}
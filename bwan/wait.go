@@ -0,0 +1,80 @@
+package bwan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	swagger "github.com/infiotinc/netskopebwan-go-client"
+	"github.com/netskopeoss/terraform-provider-netskopebwan/utils"
+)
+
+// The wait between polls of the orchestrator grows exponentially with
+// jitter from pollWaitMin up to pollWaitMax.
+var (
+	pollWaitMin = 5 * time.Second
+	pollWaitMax = time.Minute
+)
+
+// poll calls check until it reports done or fails, backing off between
+// calls. It gives up once ctx is done, e.g. when the timeout of the
+// operation expires, reporting what it was waiting for.
+func poll(ctx context.Context, what string, check func(ctx context.Context) (bool, error)) error {
+	for attempt := 0; ; attempt++ {
+		done, err := check(ctx)
+		if ctx.Err() != nil {
+			return fmt.Errorf("gave up waiting for %s: %w", what, ctx.Err())
+		}
+		if err != nil || done {
+			return err
+		}
+
+		timer := time.NewTimer(utils.Backoff(pollWaitMin, pollWaitMax, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("gave up waiting for %s: %w", what, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// waitActivated waits until the gateway has been activated by its device.
+func waitActivated(ctx context.Context, apiSvc *apiClient, id string) error {
+	return poll(ctx, fmt.Sprintf("gateway %s to be activated", id), func(ctx context.Context) (bool, error) {
+		gateway, resp, err := apiSvc.EdgesApi.GetEdgeById(withFreshEdge(ctx), id, nil)
+		if err != nil {
+			return false, edgeAPIError{op: "GetEdgeById", resp: resp, err: err}
+		}
+
+		return gateway.Activated, nil
+	})
+}
+
+// lastStatus returns the last known status of the gateway, or a zero status
+// if its device has not reported one yet.
+func lastStatus(ctx context.Context, apiSvc *apiClient, id string) (swagger.EdgeStatusRef, error) {
+	status, resp, err := apiSvc.EdgesApi.GetEdgeStatusById(ctx, id, nil)
+	if err != nil {
+		if apiSvc.isNotFound(err, resp, "/edges/"+id+"/status") {
+			return swagger.EdgeStatusRef{}, nil
+		}
+		return status, edgeAPIError{op: "GetEdgeStatusById", resp: resp, err: err}
+	}
+
+	return status, nil
+}
+
+// waitOnline waits until the device of the gateway reports a status
+// published after since, i.e. it is up and connected to the orchestrator.
+func waitOnline(ctx context.Context, apiSvc *apiClient, id string, since time.Time) error {
+	return poll(ctx, fmt.Sprintf("gateway %s to be online", id), func(ctx context.Context) (bool, error) {
+		status, err := lastStatus(ctx, apiSvc, id)
+		if err != nil {
+			return false, err
+		}
+
+		return status.Status != nil && status.Status.DsDevstatusGeneral != nil &&
+			status.TimePublished.After(since), nil
+	})
+}
@@ -0,0 +1,5 @@
+module github.com/netskopeoss/terraform-provider-netskopebwan/tools/openapi
+
+go 1.26
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,149 @@
+// Command openapi extracts the field descriptions, enum values and defaults
+// of the schemas of the API client's OpenAPI spec into the JSON file the
+// provider embeds to describe its reflected schemas.
+//
+// The schemas are keyed by their name in lower case without underscores,
+// which is how the Go type names of the client compare to them, e.g.
+// "EdgeBGPConfiguration" and "InterfaceSettings_addresses" are found as
+// "edgebgpconfiguration" and "interfacesettingsaddresses".
+//
+// It is a module of its own, so that the YAML parser it needs is not a
+// dependency of the provider. Run it from the bwan package with go generate.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const clientModule = "github.com/infiotinc/netskopebwan-go-client"
+
+type spec struct {
+	Components struct {
+		Schemas map[string]*schemaObject `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type schemaObject struct {
+	Ref         string                   `yaml:"$ref"`
+	Description string                   `yaml:"description"`
+	Default     yaml.Node                `yaml:"default"`
+	Enum        []yaml.Node              `yaml:"enum"`
+	Properties  map[string]*schemaObject `yaml:"properties"`
+	Items       *schemaObject            `yaml:"items"`
+	AllOf       []*schemaObject          `yaml:"allOf"`
+}
+
+type field struct {
+	Description string   `json:"description,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Default     string   `json:"default,omitempty"`
+}
+
+func main() {
+	specPath := flag.String("spec", "", "path of the OpenAPI spec, defaults to the one of the client module")
+	moduleDir := flag.String("dir", ".", "directory of the provider module, whose client module version is used")
+	out := flag.String("o", "openapi.json", "output file")
+	flag.Parse()
+
+	if *specPath == "" {
+		list := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", clientModule)
+		list.Dir = *moduleDir
+		dir, err := list.Output()
+		if err != nil {
+			log.Fatalf("locating %s: %v", clientModule, err)
+		}
+		*specPath = filepath.Join(strings.TrimSpace(string(dir)), "api", "swagger.yaml")
+	}
+
+	raw, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var s spec
+	if err := yaml.Unmarshal(raw, &s); err != nil {
+		log.Fatalf("parsing %s: %v", *specPath, err)
+	}
+
+	schemas := map[string]map[string]field{}
+	for name := range s.Components.Schemas {
+		fields := map[string]field{}
+		s.collect(s.Components.Schemas[name], fields)
+		if len(fields) > 0 {
+			schemas[strings.ToLower(strings.ReplaceAll(name, "_", ""))] = fields
+		}
+	}
+
+	data, err := json.MarshalIndent(schemas, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// resolve returns the schema o refers to, if any.
+func (s spec) resolve(o *schemaObject) *schemaObject {
+	for o != nil && o.Ref != "" {
+		o = s.Components.Schemas[strings.TrimPrefix(o.Ref, "#/components/schemas/")]
+	}
+	return o
+}
+
+// collect adds the documented properties of o, including those of the
+// schemas it is composed of, to fields.
+func (s spec) collect(o *schemaObject, fields map[string]field) {
+	for _, base := range o.AllOf {
+		s.collect(s.resolve(base), fields)
+	}
+
+	for name, p := range o.Properties {
+		f := field{Description: description(name, p.Description)}
+
+		// Enums are separate schemas the property refers to.
+		target := p
+		if p.Items != nil {
+			target = p.Items
+		}
+		if ref := s.resolve(target); ref != nil && ref != target && len(ref.Properties) == 0 {
+			if f.Description == "" {
+				f.Description = description(name, ref.Description)
+			}
+			target = ref
+		}
+		for _, v := range target.Enum {
+			f.Enum = append(f.Enum, v.Value)
+		}
+		if p.Default.Value != "" {
+			f.Default = p.Default.Value
+		}
+
+		if f.Description != "" || len(f.Enum) > 0 || f.Default != "" {
+			fields[name] = f
+		}
+	}
+}
+
+// description normalizes the description of a property, dropping those
+// that only repeat its name.
+func description(name, text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if strings.EqualFold(text, name) || text == "" {
+		return ""
+	}
+
+	text = strings.ToUpper(text[:1]) + text[1:]
+	if !strings.HasSuffix(text, ".") {
+		text += "."
+	}
+	return text
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryTransport is an http.RoundTripper that retries throttled and failed
+// requests with jittered exponential backoff. Only idempotent methods are
+// retried unless RetryNonIdempotent is set, since a POST that failed midway
+// may already have been applied by the orchestrator.
+type RetryTransport struct {
+	Base               http.RoundTripper
+	MaxRetries         int
+	WaitMin            time.Duration
+	WaitMax            time.Duration
+	RequestTimeout     time.Duration
+	RetryNonIdempotent bool
+}
+
+func (t *RetryTransport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retryable := t.RetryNonIdempotent || isIdempotent(req.Method)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.roundTrip(req)
+
+		if !retryable || attempt >= t.MaxRetries || req.Context().Err() != nil ||
+			!shouldRetry(resp, err) {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			// The body has been consumed and cannot be replayed.
+			return resp, err
+		}
+
+		wait := Backoff(t.WaitMin, t.WaitMax, attempt)
+		if resp != nil {
+			if after, ok := RetryAfter(resp); ok {
+				// The header is honored, but not beyond the longest wait
+				// the provider is configured for.
+				wait = after
+				if t.WaitMax > 0 {
+					wait = min(wait, t.WaitMax)
+				}
+			}
+		}
+		// Waiting past the deadline would only replace the response with a
+		// context error.
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < wait {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// roundTrip performs a single attempt, bounded by RequestTimeout. The
+// attempt's context is released once the response body is closed.
+func (t *RetryTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	if t.RequestTimeout <= 0 {
+		return t.base().RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.RequestTimeout)
+	resp, err := t.base().RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Backoff returns the wait before the given retry attempt: an exponentially
+// growing delay capped at max, of which the upper half is randomized.
+func Backoff(min, max time.Duration, attempt int) time.Duration {
+	wait := min
+	for i := 0; i < attempt && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	if wait <= 0 {
+		return 0
+	}
+
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
+
+// RetryAfter parses the Retry-After header of a response, given either in
+// seconds or as an HTTP date.
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(v); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,250 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyServer fails the first `failures` requests with the given status and
+// records the bodies it received.
+func flakyServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *int32, *[]string) {
+	var calls int32
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		if atomic.AddInt32(&calls, 1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls, &bodies
+}
+
+func testClient(t *RetryTransport) *http.Client {
+	if t.WaitMin == 0 {
+		t.WaitMin = time.Millisecond
+		t.WaitMax = 5 * time.Millisecond
+	}
+	return &http.Client{Transport: t}
+}
+
+func TestRetryTransportRetriesIdempotent(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{"throttled", http.StatusTooManyRequests},
+		{"bad gateway", http.StatusBadGateway},
+		{"unavailable", http.StatusServiceUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv, calls, _ := flakyServer(t, 2, test.status, nil)
+			client := testClient(&RetryTransport{MaxRetries: 3})
+
+			resp, err := client.Get(srv.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.EqualValues(t, 3, atomic.LoadInt32(calls))
+		})
+	}
+}
+
+func TestRetryTransportGivesUp(t *testing.T) {
+	srv, calls, _ := flakyServer(t, 10, http.StatusServiceUnavailable, nil)
+	client := testClient(&RetryTransport{MaxRetries: 2})
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.EqualValues(t, 3, atomic.LoadInt32(calls))
+}
+
+func TestRetryTransportDoesNotRetryClientErrors(t *testing.T) {
+	srv, calls, _ := flakyServer(t, 10, http.StatusBadRequest, nil)
+	client := testClient(&RetryTransport{MaxRetries: 2})
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+}
+
+func TestRetryTransportNonIdempotent(t *testing.T) {
+	srv, calls, _ := flakyServer(t, 1, http.StatusBadGateway, nil)
+	client := testClient(&RetryTransport{MaxRetries: 2})
+
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{"name":"gw"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+
+	srv, calls, bodies := flakyServer(t, 1, http.StatusBadGateway, nil)
+	client = testClient(&RetryTransport{MaxRetries: 2, RetryNonIdempotent: true})
+
+	resp, err = client.Post(srv.URL, "application/json", strings.NewReader(`{"name":"gw"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+	assert.Equal(t, []string{`{"name":"gw"}`, `{"name":"gw"}`}, *bodies)
+}
+
+func TestRetryTransportReplaysBody(t *testing.T) {
+	srv, _, bodies := flakyServer(t, 2, http.StatusServiceUnavailable, nil)
+	client := testClient(&RetryTransport{MaxRetries: 2})
+
+	req, err := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader(`{"staticRoutes":[]}`))
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{`{"staticRoutes":[]}`, `{"staticRoutes":[]}`, `{"staticRoutes":[]}`}, *bodies)
+}
+
+func TestRetryTransportHonorsRetryAfter(t *testing.T) {
+	srv, calls, _ := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+	client := testClient(&RetryTransport{MaxRetries: 1, WaitMin: time.Millisecond, WaitMax: 2 * time.Second})
+
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestRetryTransportCapsRetryAfter(t *testing.T) {
+	srv, calls, _ := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"3600"}})
+	client := testClient(&RetryTransport{MaxRetries: 1, WaitMin: time.Millisecond, WaitMax: 10 * time.Millisecond})
+
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryTransportDeadline(t *testing.T) {
+	srv, calls, _ := flakyServer(t, 10, http.StatusTooManyRequests, http.Header{"Retry-After": {"60"}})
+	client := testClient(&RetryTransport{MaxRetries: 5, WaitMin: time.Millisecond, WaitMax: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	// The wait would outlast the deadline, so the throttled response is
+	// returned right away.
+	start := time.Now()
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryTransportContextCancel(t *testing.T) {
+	srv, calls, _ := flakyServer(t, 10, http.StatusTooManyRequests, http.Header{"Retry-After": {"60"}})
+	client := testClient(&RetryTransport{MaxRetries: 5, WaitMin: time.Millisecond, WaitMax: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	_, err = client.Do(req)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+}
+
+func TestRetryTransportRequestTimeout(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client := testClient(&RetryTransport{MaxRetries: 1, RequestTimeout: 50 * time.Millisecond})
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, `{}`, string(body))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		wait := Backoff(100*time.Millisecond, time.Second, attempt)
+
+		ceiling := 100 * time.Millisecond << attempt
+		if ceiling > time.Second {
+			ceiling = time.Second
+		}
+		assert.GreaterOrEqual(t, wait, ceiling/2)
+		assert.LessOrEqual(t, wait, ceiling)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	wait, ok := RetryAfter(&http.Response{Header: http.Header{"Retry-After": {"7"}}})
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, wait)
+
+	at := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	wait, ok = RetryAfter(&http.Response{Header: http.Header{"Retry-After": {at}}})
+	assert.True(t, ok)
+	assert.InDelta(t, float64(time.Minute), float64(wait), float64(2*time.Second))
+
+	_, ok = RetryAfter(&http.Response{Header: http.Header{}})
+	assert.False(t, ok)
+}
@@ -19,10 +19,21 @@ type KeyMutex struct {
 	mu sync.Mutex
 }
 
+// GetExistingNat returns the index of the rule named like natEntry. Rules
+// without a name, e.g. of states upgraded from before names were required,
+// are matched by their uplink, public address and port and LAN address
+// instead, as a gateway may have several of them.
 func GetExistingNat(natRules []swagger.InboundNatRule,
 	natEntry swagger.InboundNatRule) (index int) {
 	for index, nat := range natRules {
-		if nat.Name == natEntry.Name {
+		if nat.Name != natEntry.Name {
+			continue
+		}
+		if natEntry.Name != "" ||
+			(nat.UpLinkIfName == natEntry.UpLinkIfName &&
+				nat.PublicIp == natEntry.PublicIp &&
+				nat.PublicPort == natEntry.PublicPort &&
+				nat.LanIp == natEntry.LanIp) {
 			return index
 		}
 	}